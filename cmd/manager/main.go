@@ -21,6 +21,14 @@ import (
 
 	"github.com/percona/percona-server-mongodb-operator/pkg/apis"
 	"github.com/percona/percona-server-mongodb-operator/pkg/controller"
+	"github.com/percona/percona-server-mongodb-operator/pkg/metrics"
+	"github.com/percona/percona-server-mongodb-operator/pkg/webhook"
+)
+
+// Metrics are served at http://<metricsHost>:<metricsPort>/metrics
+const (
+	metricsHost       = "0.0.0.0"
+	metricsPort int32 = 60000
 )
 
 var (
@@ -71,8 +79,15 @@ func main() {
 	}
 	defer r.Unset()
 
+	// Admission webhooks are served only if the certificates are provided
+	webhookCertDir := os.Getenv("WEBHOOK_CERT_DIR")
+
 	// Create a new Cmd to provide shared dependencies and start components
-	mgr, err := manager.New(cfg, manager.Options{Namespace: namespace})
+	mgr, err := manager.New(cfg, manager.Options{
+		Namespace:          namespace,
+		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		CertDir:            webhookCertDir,
+	})
 	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
@@ -92,12 +107,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	metrics.Register(mgr.GetClient())
+
 	// Setup all Controllers
 	if err := controller.AddToManager(mgr); err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
 
+	if webhookCertDir != "" {
+		if err := webhook.AddToManager(mgr); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	}
+
 	log.Info("Starting the Cmd.")
 
 	// Start the Cmd
@@ -16,6 +16,7 @@ require (
 	github.com/operator-framework/operator-sdk v0.17.1
 	github.com/percona/percona-backup-mongodb v1.2.0
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.5.1
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.6.1
 	go.mongodb.org/mongo-driver v1.3.4
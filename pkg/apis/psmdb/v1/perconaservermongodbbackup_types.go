@@ -16,6 +16,9 @@ type PerconaServerMongoDBBackupSpec struct {
 	PSMDBCluster string              `json:"psmdbCluster,omitempty"`
 	StorageName  string              `json:"storageName,omitempty"`
 	Comperssion  pbm.CompressionType `json:"compressionType,omitempty"`
+	// DeleteFromStorage deletes the backup data from the storage
+	// once the object is deleted. Only S3 storages are supported.
+	DeleteFromStorage bool `json:"deleteFromStorage,omitempty"`
 }
 
 type BackupState string
@@ -34,15 +37,25 @@ const (
 type PerconaServerMongoDBBackupStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
-	State          BackupState          `json:"state,omitempty"`
-	StartAt        *metav1.Time         `json:"start,omitempty"`
-	CompletedAt    *metav1.Time         `json:"completed,omitempty"`
-	LastTransition *metav1.Time         `json:"lastTransition,omitempty"`
-	Destination    string               `json:"destination,omitempty"`
-	StorageName    string               `json:"storageName,omitempty"`
-	S3             *BackupStorageS3Spec `json:"s3,omitempty"`
-	PBMname        string               `json:"pbmName,omitempty"`
-	Error          string               `json:"error,omitempty"`
+	State          BackupState             `json:"state,omitempty"`
+	StartAt        *metav1.Time            `json:"start,omitempty"`
+	CompletedAt    *metav1.Time            `json:"completed,omitempty"`
+	LastTransition *metav1.Time            `json:"lastTransition,omitempty"`
+	Destination    string                  `json:"destination,omitempty"`
+	StorageName    string                  `json:"storageName,omitempty"`
+	S3             *BackupStorageS3Spec    `json:"s3,omitempty"`
+	Azure          *BackupStorageAzureSpec `json:"azure,omitempty"`
+	GCS            *BackupStorageGCSSpec   `json:"gcs,omitempty"`
+	PBMname        string                  `json:"pbmName,omitempty"`
+	Error          string                  `json:"error,omitempty"`
+	Replsets       []BackupReplsetStatus   `json:"replsets,omitempty"`
+}
+
+// BackupReplsetStatus is the state of the backup of a single replset
+type BackupReplsetStatus struct {
+	Name  string      `json:"name"`
+	State BackupState `json:"state,omitempty"`
+	Error string      `json:"error,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -36,11 +36,19 @@ const (
 // PerconaServerMongoDBRestoreStatus defines the observed state of PerconaServerMongoDBRestore
 type PerconaServerMongoDBRestoreStatus struct {
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
-	State          RestoreState `json:"state,omitempty"`
-	PBMname        string       `json:"pbmName,omitempty"`
-	Error          string       `json:"error,omitempty"`
-	CompletedAt    *metav1.Time `json:"completed,omitempty"`
-	LastTransition *metav1.Time `json:"lastTransition,omitempty"`
+	State          RestoreState           `json:"state,omitempty"`
+	PBMname        string                 `json:"pbmName,omitempty"`
+	Error          string                 `json:"error,omitempty"`
+	CompletedAt    *metav1.Time           `json:"completed,omitempty"`
+	LastTransition *metav1.Time           `json:"lastTransition,omitempty"`
+	Replsets       []RestoreReplsetStatus `json:"replsets,omitempty"`
+}
+
+// RestoreReplsetStatus is the state of the restore of a single replset
+type RestoreReplsetStatus struct {
+	Name  string       `json:"name"`
+	State RestoreState `json:"state,omitempty"`
+	Error string       `json:"error,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -16,23 +16,27 @@ import (
 // DefaultDNSSuffix is a default dns suffix for the cluster service
 const DefaultDNSSuffix = "svc.cluster.local"
 
+// DefaultUsersSecretName is a default name of the secret with the system users
+const DefaultUsersSecretName = "percona-server-mongodb-users"
+
 var (
 	defaultRunUID                   int64 = 1001
-	defaultUsersSecretName                = "percona-server-mongodb-users"
 	defaultMongodSize               int32 = 3
 	defaultReplsetName                    = "rs"
 	defaultStorageEngine                  = StorageEngineWiredTiger
 	defaultMongodPort               int32 = 27017
+	defaultMongosSize               int32 = 3
 	defaultWiredTigerCacheSizeRatio       = 0.5
 	defaultInMemorySizeRatio              = 0.9
 	defaultOperationProfilingMode         = OperationProfilingModeSlowOp
 	defaultImagePullPolicy                = corev1.PullAlways
+	defaultReplicationLagThreshold  int64 = 60
 )
 
 // CheckNSetDefaults sets default options, overwrites wrong settings
 // and checks if other options' values valid
 func (cr *PerconaServerMongoDB) CheckNSetDefaults(platform version.Platform, log logr.Logger) error {
-	err := cr.setVersion()
+	err := cr.SetVersion()
 	if err != nil {
 		return errors.Wrap(err, "set version")
 	}
@@ -46,8 +50,14 @@ func (cr *PerconaServerMongoDB) CheckNSetDefaults(platform version.Platform, log
 	if cr.Spec.Secrets == nil {
 		cr.Spec.Secrets = &SecretsSpec{}
 	}
+	if cr.Spec.ReplicationLagThreshold == 0 {
+		cr.Spec.ReplicationLagThreshold = defaultReplicationLagThreshold
+	}
+	if cr.Spec.ReplicationLagThreshold < 0 {
+		return fmt.Errorf("replicationLagThresholdSeconds should not be negative")
+	}
 	if cr.Spec.Secrets.Users == "" {
-		cr.Spec.Secrets.Users = defaultUsersSecretName
+		cr.Spec.Secrets.Users = DefaultUsersSecretName
 	}
 	if cr.Spec.Mongod == nil {
 		cr.Spec.Mongod = &MongodSpec{}
@@ -129,6 +139,15 @@ func (cr *PerconaServerMongoDB) CheckNSetDefaults(platform version.Platform, log
 		}
 	}
 
+	// every replset has its own StatefulSet and services named after it
+	rsNames := make(map[string]struct{}, len(cr.Spec.Replsets))
+	for _, replset := range cr.Spec.Replsets {
+		if _, ok := rsNames[replset.Name]; ok {
+			return fmt.Errorf("replset name %q is not unique", replset.Name)
+		}
+		rsNames[replset.Name] = struct{}{}
+	}
+
 	gte140 := cr.CompareVersion("1.4.0") >= 0
 
 	timeoutSecondsDefault := int32(5)
@@ -205,7 +224,28 @@ func (cr *PerconaServerMongoDB) CheckNSetDefaults(platform version.Platform, log
 		}
 		if cr.Spec.Pause {
 			replset.Size = 0
-			replset.Arbiter.Enabled = false
+			replset.Arbiter.Size = 0
+			for _, name := range MemberPools {
+				if pool := replset.MemberPool(name); pool != nil {
+					pool.Size = 0
+				}
+			}
+		}
+	}
+
+	if cr.Spec.Sharding.Enabled {
+		err := cr.checkShardingReplsets()
+		if err != nil {
+			return errors.Wrap(err, "sharding")
+		}
+
+		if cr.Spec.Sharding.Mongos == nil {
+			cr.Spec.Sharding.Mongos = &MongosSpec{}
+		}
+		cr.Spec.Sharding.Mongos.SetDefaults(platform)
+
+		if cr.Spec.Pause {
+			cr.Spec.Sharding.Mongos.Size = 0
 		}
 	}
 
@@ -218,11 +258,31 @@ func (cr *PerconaServerMongoDB) CheckNSetDefaults(platform version.Platform, log
 		cr.Spec.Backup.Enabled = false
 	}
 
+	if cr.Spec.DeletionPolicy != nil {
+		err := cr.Spec.DeletionPolicy.setDefaults(&cr.Spec.Backup)
+		if err != nil {
+			return fmt.Errorf("deletionPolicy: %v", err)
+		}
+	}
+
 	if cr.Spec.Backup.Enabled {
 		for _, bkpTask := range cr.Spec.Backup.Tasks {
 			if string(bkpTask.CompressionType) == "" {
 				bkpTask.CompressionType = pbm.CompressionTypeGZIP
 			}
+			if bkpTask.Keep < 0 {
+				return fmt.Errorf("backup task %s: keep should not be negative", bkpTask.Name)
+			}
+		}
+		err := cr.Spec.Backup.SetStoragesDefaults()
+		if err != nil {
+			return err
+		}
+		for _, bkpTask := range cr.Spec.Backup.Tasks {
+			stg, ok := cr.Spec.Backup.Storages[bkpTask.StorageName]
+			if ok && bkpTask.HasRetention() && !stg.Type.OperatorAccessible() {
+				return fmt.Errorf("backup task %s: keep and maxAge aren't supported for %s storage %s", bkpTask.Name, stg.Type, bkpTask.StorageName)
+			}
 		}
 		if len(cr.Spec.Backup.ServiceAccountName) == 0 {
 			cr.Spec.Backup.ServiceAccountName = "percona-server-mongodb-operator"
@@ -259,6 +319,64 @@ func (cr *PerconaServerMongoDB) CheckNSetDefaults(platform version.Platform, log
 	return nil
 }
 
+// setDefaults checks the pool and sets the options
+// which aren't specified to the ones of the replset
+func (p *MemberPool) setDefaults(name string, rs *ReplsetSpec) error {
+	if p.Size < 0 {
+		return fmt.Errorf("size should not be negative")
+	}
+
+	switch name {
+	case MemberPoolDelayed:
+		if p.DelaySecs <= 0 {
+			return fmt.Errorf("delaySecs should be positive")
+		}
+	default:
+		if p.DelaySecs != 0 {
+			return fmt.Errorf("delaySecs is allowed for delayed members only")
+		}
+	}
+
+	if p.Resources == nil {
+		p.Resources = rs.Resources.DeepCopy()
+	}
+
+	if p.VolumeSpec == nil {
+		p.VolumeSpec = rs.VolumeSpec.DeepCopy()
+	}
+	err := p.VolumeSpec.reconcileOpts()
+	if err != nil {
+		return fmt.Errorf("VolumeSpec: %v", err)
+	}
+
+	p.MultiAZ.reconcileOpts()
+
+	return nil
+}
+
+func (c *ReplsetConfig) check() error {
+	if c.ElectionTimeoutMillis < 0 {
+		return fmt.Errorf("electionTimeoutMillis should not be negative")
+	}
+	if c.HeartbeatTimeoutSecs < 0 {
+		return fmt.Errorf("heartbeatTimeoutSecs should not be negative")
+	}
+	for mode, tags := range c.GetLastErrorModes {
+		for tag, n := range tags {
+			if n < 1 {
+				return fmt.Errorf("getLastErrorModes %s: number of %s tag values should be positive", mode, tag)
+			}
+		}
+	}
+	for _, p := range c.Priorities {
+		if p.Priority < 0 || p.Priority > 1000 {
+			return fmt.Errorf("priority %d should be in range [0, 1000]", p.Priority)
+		}
+	}
+
+	return nil
+}
+
 // SetDefauts set default options for the replset
 func (rs *ReplsetSpec) SetDefauts(platform version.Platform, unsafe bool, log logr.Logger) error {
 	if rs.VolumeSpec == nil {
@@ -274,12 +392,30 @@ func (rs *ReplsetSpec) SetDefauts(platform version.Platform, unsafe bool, log lo
 		rs.Expose.ExposeType = corev1.ServiceTypeClusterIP
 	}
 
+	if rs.ReplsetConfig != nil {
+		err := rs.ReplsetConfig.check()
+		if err != nil {
+			return fmt.Errorf("replset %s replsetConfig: %v", rs.Name, err)
+		}
+	}
+
 	rs.MultiAZ.reconcileOpts()
 
 	if rs.Arbiter.Enabled {
 		rs.Arbiter.MultiAZ.reconcileOpts()
 	}
 
+	for _, name := range MemberPools {
+		pool := rs.MemberPool(name)
+		if pool == nil {
+			continue
+		}
+		err := pool.setDefaults(name, rs)
+		if err != nil {
+			return fmt.Errorf("replset %s %s members: %v", rs.Name, name, err)
+		}
+	}
+
 	if !unsafe {
 		rs.setSafeDefauts(log)
 	}
@@ -306,6 +442,150 @@ func (rs *ReplsetSpec) SetDefauts(platform version.Platform, unsafe bool, log lo
 	return nil
 }
 
+func (p *DeletionPolicy) setDefaults(b *BackupSpec) error {
+	for _, a := range []*DeletionAction{&p.PVCs, &p.Secrets} {
+		switch *a {
+		case "":
+			*a = DeletionRetain
+		case DeletionRetain, DeletionDelete:
+		default:
+			return fmt.Errorf("unknown action %s, should be %s or %s", *a, DeletionRetain, DeletionDelete)
+		}
+	}
+
+	if p.FinalBackup == nil || !p.FinalBackup.Enabled {
+		return nil
+	}
+	if p.FinalBackup.StorageName == "" {
+		return fmt.Errorf("finalBackup: storageName should be specified")
+	}
+	if _, ok := b.Storages[p.FinalBackup.StorageName]; b.Enabled && !ok {
+		return fmt.Errorf("finalBackup: storage %s doesn't exist", p.FinalBackup.StorageName)
+	}
+	if string(p.FinalBackup.CompressionType) == "" {
+		p.FinalBackup.CompressionType = pbm.CompressionTypeGZIP
+	}
+
+	return nil
+}
+
+// SetStoragesDefaults checks backup storages and sets their default options
+func (b *BackupSpec) SetStoragesDefaults() error {
+	for name, stg := range b.Storages {
+		err := stg.CheckFields()
+		if err != nil {
+			return fmt.Errorf("backup storage %s: %v", name, err)
+		}
+		if stg.Type == BackupStorageFilesystem && stg.Filesystem.Path == "" {
+			stg.Filesystem.Path = "/backups/" + name
+		}
+	}
+
+	return nil
+}
+
+// CheckFields checks that the options of the storage type are specified.
+// Azure and GCS storages are rejected, as the agents of the bundled
+// PBM v1.2.0 can't use them.
+func (s *BackupStorageSpec) CheckFields() error {
+	switch s.Type {
+	case BackupStorageFilesystem:
+		if s.Filesystem == nil || (s.Filesystem.PersistentVolumeClaim == nil) == (s.Filesystem.NFS == nil) {
+			return errors.New("exactly one of persistentVolumeClaim or nfs should be specified")
+		}
+	case BackupStorageAzure, BackupStorageGCS:
+		return fmt.Errorf("%s storage isn't supported by the bundled PBM v1.2.0", s.Type)
+	}
+
+	return nil
+}
+
+// checkShardingReplsets checks that replsets are suitable for a sharded cluster:
+// exactly one config server replset and at least one shard
+func (cr *PerconaServerMongoDB) checkShardingReplsets() error {
+	cfgsvr, shards := 0, 0
+	for _, rs := range cr.Spec.Replsets {
+		switch rs.ClusterRole {
+		case ClusterRoleConfigSvr:
+			cfgsvr++
+		case ClusterRoleShardSvr:
+			shards++
+		default:
+			return fmt.Errorf("replset %s: clusterRole should be %s or %s", rs.Name, ClusterRoleShardSvr, ClusterRoleConfigSvr)
+		}
+	}
+
+	if cfgsvr != 1 {
+		return fmt.Errorf("exactly one replset with clusterRole %s should be specified, got %d", ClusterRoleConfigSvr, cfgsvr)
+	}
+	if shards == 0 {
+		return fmt.Errorf("at least one replset with clusterRole %s should be specified", ClusterRoleShardSvr)
+	}
+
+	return nil
+}
+
+// SetDefaults set default options for mongos
+func (m *MongosSpec) SetDefaults(platform version.Platform) {
+	if m.Size == 0 {
+		m.Size = defaultMongosSize
+	}
+	if m.Port == 0 {
+		m.Port = defaultMongodPort
+	}
+	if m.Expose.ExposeType == "" {
+		m.Expose.ExposeType = corev1.ServiceTypeClusterIP
+	}
+
+	if m.ReadinessProbe == nil {
+		m.ReadinessProbe = &corev1.Probe{
+			Handler: corev1.Handler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(m.Port)),
+				},
+			},
+			InitialDelaySeconds: 10,
+			TimeoutSeconds:      2,
+			PeriodSeconds:       3,
+			FailureThreshold:    8,
+		}
+	}
+	if m.LivenessProbe == nil {
+		m.LivenessProbe = &corev1.Probe{
+			Handler: corev1.Handler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromInt(int(m.Port)),
+				},
+			},
+			InitialDelaySeconds: 60,
+			TimeoutSeconds:      5,
+			PeriodSeconds:       30,
+			FailureThreshold:    4,
+		}
+	}
+
+	m.MultiAZ.reconcileOpts()
+
+	var fsgroup *int64
+	if platform == version.PlatformKubernetes {
+		var tp int64 = 1001
+		fsgroup = &tp
+	}
+
+	if m.ContainerSecurityContext == nil {
+		tvar := true
+		m.ContainerSecurityContext = &corev1.SecurityContext{
+			RunAsNonRoot: &tvar,
+			RunAsUser:    fsgroup,
+		}
+	}
+	if m.PodSecurityContext == nil {
+		m.PodSecurityContext = &corev1.PodSecurityContext{
+			FSGroup: fsgroup,
+		}
+	}
+}
+
 func (rs *ReplsetSpec) setSafeDefauts(log logr.Logger) {
 	loginfo := func(msg string, args ...interface{}) {
 		log.Info(msg, args...)
@@ -337,6 +617,27 @@ func (rs *ReplsetSpec) setSafeDefauts(log logr.Logger) {
 	}
 }
 
+// CheckSafeConfig returns an error if the replset's configuration
+// would be changed by setSafeDefauts
+func (rs *ReplsetSpec) CheckSafeConfig() error {
+	if rs.Size < 2 {
+		return fmt.Errorf("replset %s: size %d is less than 2, set allowUnsafeConfigurations=true to allow it", rs.Name, rs.Size)
+	}
+
+	if rs.Arbiter.Enabled {
+		if rs.Arbiter.Size != 1 {
+			return fmt.Errorf("replset %s: arbiter size %d is not 1, set allowUnsafeConfigurations=true to allow it", rs.Name, rs.Arbiter.Size)
+		}
+		if rs.Size%2 != 0 {
+			return fmt.Errorf("replset %s: arbiter with odd size %d, set allowUnsafeConfigurations=true to allow it", rs.Name, rs.Size)
+		}
+	} else if rs.Size%2 == 0 {
+		return fmt.Errorf("replset %s: even size %d, set allowUnsafeConfigurations=true to allow it", rs.Name, rs.Size)
+	}
+
+	return nil
+}
+
 func (m *MultiAZ) reconcileOpts() {
 	m.reconcileAffinityOpts()
 
@@ -398,5 +699,38 @@ func (v *VolumeSpec) reconcileOpts() error {
 		}
 	}
 
+	if v.StorageAutoscaling != nil && v.StorageAutoscaling.Enabled {
+		err := v.StorageAutoscaling.reconcileOpts(v.PersistentVolumeClaim)
+		if err != nil {
+			return fmt.Errorf("storageAutoscaling: %v", err)
+		}
+	}
+
+	return nil
+}
+
+const defaultStorageThresholdPercent = 80
+
+func (a *StorageAutoscaling) reconcileOpts(pvc *corev1.PersistentVolumeClaimSpec) error {
+	if pvc == nil {
+		return fmt.Errorf("persistentVolumeClaim should be specified")
+	}
+
+	if a.ThresholdPercent == 0 {
+		a.ThresholdPercent = defaultStorageThresholdPercent
+	}
+	if a.ThresholdPercent < 1 || a.ThresholdPercent > 99 {
+		return fmt.Errorf("thresholdPercent %d should be in range [1, 99]", a.ThresholdPercent)
+	}
+
+	if a.GrowthStep.Sign() <= 0 {
+		return fmt.Errorf("growthStep should be positive")
+	}
+
+	size := pvc.Resources.Requests[corev1.ResourceStorage]
+	if a.MaxSize.Cmp(size) < 0 {
+		return fmt.Errorf("maxSize %s should not be less than the requested storage %s", a.MaxSize.String(), size.String())
+	}
+
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
 	"github.com/percona/percona-server-mongodb-operator/version"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -209,3 +210,75 @@ func TestSetSafeDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestSetStoragesDefaults(t *testing.T) {
+	tests := map[string]struct {
+		storage api.BackupStorageSpec
+		wantErr bool
+	}{
+		"s3": {
+			storage: api.BackupStorageSpec{
+				Type: api.BackupStorageS3,
+				S3:   api.BackupStorageS3Spec{Bucket: "bucket", CredentialsSecret: "s3-secret"},
+			},
+		},
+		"s3 without credentials": {
+			storage: api.BackupStorageSpec{
+				Type: api.BackupStorageS3,
+				S3:   api.BackupStorageS3Spec{Bucket: "bucket"},
+			},
+		},
+		"filesystem with nfs": {
+			storage: api.BackupStorageSpec{
+				Type:       api.BackupStorageFilesystem,
+				Filesystem: &api.BackupStorageFilesystemSpec{NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/exports"}},
+			},
+		},
+		"filesystem without volume": {
+			storage: api.BackupStorageSpec{
+				Type:       api.BackupStorageFilesystem,
+				Filesystem: &api.BackupStorageFilesystemSpec{},
+			},
+			wantErr: true,
+		},
+		"filesystem with two volumes": {
+			storage: api.BackupStorageSpec{
+				Type: api.BackupStorageFilesystem,
+				Filesystem: &api.BackupStorageFilesystemSpec{
+					NFS:                   &corev1.NFSVolumeSource{Server: "nfs", Path: "/exports"},
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "backups"},
+				},
+			},
+			wantErr: true,
+		},
+		"azure": {
+			storage: api.BackupStorageSpec{
+				Type:  api.BackupStorageAzure,
+				Azure: &api.BackupStorageAzureSpec{Container: "container", CredentialsSecret: "azure-secret"},
+			},
+			wantErr: true,
+		},
+		"gcs": {
+			storage: api.BackupStorageSpec{
+				Type: api.BackupStorageGCS,
+				GCS:  &api.BackupStorageGCSSpec{Bucket: "bucket", CredentialsSecret: "gcs-secret"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := api.BackupSpec{Storages: map[string]api.BackupStorageSpec{"stg": test.storage}}
+			err := b.SetStoragesDefaults()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if test.storage.Type == api.BackupStorageFilesystem {
+				assert.Equal(t, "/backups/stg", b.Storages["stg"].Filesystem.Path)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/percona/percona-backup-mongodb/pbm"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -56,6 +57,7 @@ type PerconaServerMongoDBSpec struct {
 	UnsafeConf              bool                                 `json:"allowUnsafeConfigurations"`
 	Mongod                  *MongodSpec                          `json:"mongod,omitempty"`
 	Replsets                []*ReplsetSpec                       `json:"replsets,omitempty"`
+	Sharding                Sharding                             `json:"sharding,omitempty"`
 	Secrets                 *SecretsSpec                         `json:"secrets,omitempty"`
 	Backup                  BackupSpec                           `json:"backup,omitempty"`
 	ImagePullPolicy         corev1.PullPolicy                    `json:"imagePullPolicy,omitempty"`
@@ -64,6 +66,83 @@ type PerconaServerMongoDBSpec struct {
 	UpgradeOptions          UpgradeOptions                       `json:"upgradeOptions,omitempty"`
 	SchedulerName           string                               `json:"schedulerName,omitempty"`
 	ClusterServiceDNSSuffix string                               `json:"clusterServiceDNSSuffix,omitempty"`
+	// ReplicationLagThreshold is the replication lag in seconds after
+	// which the cluster gets the ReplicationLagging condition
+	ReplicationLagThreshold int64 `json:"replicationLagThresholdSeconds,omitempty"`
+	// DeletionPolicy tells what happens to the data volumes and
+	// secrets of the cluster once it's deleted
+	DeletionPolicy *DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// DeletionProtection blocks the deletion of the cluster and the changes
+	// of its spec which would lose the data until it's turned off
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+}
+
+type DeletionAction string
+
+const (
+	DeletionRetain DeletionAction = "retain"
+	DeletionDelete DeletionAction = "delete"
+)
+
+const (
+	// FinalizerDeletionPolicy holds the deleted cluster until
+	// its deletion policy is applied
+	FinalizerDeletionPolicy = "percona.com/deletion-policy"
+	// FinalizerDeleteBackup holds the deleted backup
+	// until its data is deleted from the storage
+	FinalizerDeleteBackup = "percona.com/delete-backup"
+	// FinalizerDeletionProtection holds the deleted cluster
+	// until its deletion protection is turned off
+	FinalizerDeletionProtection = "percona.com/deletion-protection"
+)
+
+type DeletionPolicy struct {
+	// PVCs are the data volumes of the replsets
+	PVCs DeletionAction `json:"pvcs,omitempty"`
+	// Secrets are the users, internal users, TLS and key secrets
+	Secrets DeletionAction `json:"secrets,omitempty"`
+	// FinalBackup is made before anything is deleted. If it fails,
+	// the volumes and secrets are retained whatever the policy is.
+	FinalBackup *FinalBackupSpec `json:"finalBackup,omitempty"`
+}
+
+type FinalBackupSpec struct {
+	Enabled         bool                `json:"enabled,omitempty"`
+	StorageName     string              `json:"storageName,omitempty"`
+	CompressionType pbm.CompressionType `json:"compressionType,omitempty"`
+}
+
+// NeedsFinalizer returns whether there is anything
+// to be done before the cluster is deleted
+func (p *DeletionPolicy) NeedsFinalizer() bool {
+	if p == nil {
+		return false
+	}
+
+	return p.PVCs == DeletionDelete || p.Secrets == DeletionDelete ||
+		(p.FinalBackup != nil && p.FinalBackup.Enabled)
+}
+
+// HasFinalizer returns whether the object has the finalizer
+func HasFinalizer(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RemoveFinalizer removes the finalizer from the object
+func RemoveFinalizer(obj metav1.Object, finalizer string) {
+	finalizers := make([]string, 0, len(obj.GetFinalizers()))
+	for _, f := range obj.GetFinalizers() {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	obj.SetFinalizers(finalizers)
 }
 
 const (
@@ -77,19 +156,53 @@ type UpgradeOptions struct {
 }
 
 type ReplsetMemberStatus struct {
-	Name    string `json:"name,omitempty"`
-	Version string `json:"version,omitempty"`
+	Name          string       `json:"name,omitempty"`
+	Version       string       `json:"version,omitempty"`
+	State         string       `json:"state,omitempty"`
+	Healthy       bool         `json:"healthy"`
+	OptimeDate    *metav1.Time `json:"optimeDate,omitempty"`
+	LagSeconds    int64        `json:"lagSeconds,omitempty"`
+	SyncSource    string       `json:"syncSource,omitempty"`
+	UptimeSeconds int64        `json:"uptimeSeconds,omitempty"`
+	LastHeartbeat *metav1.Time `json:"lastHeartbeat,omitempty"`
 }
 
 type ReplsetStatus struct {
 	Members     []*ReplsetMemberStatus `json:"members,omitempty"`
+	Primary     string                 `json:"primary,omitempty"`
 	ClusterRole ClusterRole            `json:"clusterRole,omitempty"`
 
-	Initialized bool     `json:"initialized,omitempty"`
-	Size        int32    `json:"size"`
-	Ready       int32    `json:"ready"`
-	Status      AppState `json:"status,omitempty"`
-	Message     string   `json:"message,omitempty"`
+	Initialized  bool     `json:"initialized,omitempty"`
+	AddedAsShard *bool    `json:"addedAsShard,omitempty"`
+	Size         int32    `json:"size"`
+	Ready        int32    `json:"ready"`
+	Status       AppState `json:"status,omitempty"`
+	Message      string   `json:"message,omitempty"`
+
+	StorageAutoscaling *StorageAutoscalingStatus `json:"storageAutoscaling,omitempty"`
+}
+
+// StorageAutoscalingStatus keeps the storage the volumes of the replset's
+// components were grown to and the history of the growths
+type StorageAutoscalingStatus struct {
+	Sizes   map[string]resource.Quantity `json:"sizes,omitempty"`
+	Actions []StorageScalingAction       `json:"actions,omitempty"`
+}
+
+type StorageScalingAction struct {
+	Time        metav1.Time       `json:"time"`
+	Component   string            `json:"component"`
+	Member      string            `json:"member,omitempty"`
+	UsedPercent int               `json:"usedPercent"`
+	From        resource.Quantity `json:"from"`
+	To          resource.Quantity `json:"to"`
+}
+
+type MongosStatus struct {
+	Size    int32    `json:"size"`
+	Ready   int32    `json:"ready"`
+	Status  AppState `json:"status,omitempty"`
+	Message string   `json:"message,omitempty"`
 }
 
 type AppState string
@@ -99,6 +212,7 @@ const (
 	AppStateInit    AppState = "initializing"
 	AppStateReady   AppState = "ready"
 	AppStateError   AppState = "error"
+	AppStatePaused  AppState = "paused"
 )
 
 type UpgradeStrategy string
@@ -114,17 +228,19 @@ const (
 
 // PerconaServerMongoDBStatus defines the observed state of PerconaServerMongoDB
 type PerconaServerMongoDBStatus struct {
-	State              AppState                  `json:"state,omitempty"`
-	MongoVersion       string                    `json:"mongoVersion,omitempty"`
-	MongoImage         string                    `json:"mongoImage,omitempty"`
-	Message            string                    `json:"message,omitempty"`
-	Conditions         []ClusterCondition        `json:"conditions,omitempty"`
-	Replsets           map[string]*ReplsetStatus `json:"replsets,omitempty"`
-	ObservedGeneration int64                     `json:"observedGeneration,omitempty"`
-	BackupStatus       AppState                  `json:"backup,omitempty"`
-	BackupVersion      string                    `json:"backupVersion,omitempty"`
-	PMMStatus          AppState                  `json:"pmmStatus,omitempty"`
-	PMMVersion         string                    `json:"pmmVersion,omitempty"`
+	State              AppState                     `json:"state,omitempty"`
+	MongoVersion       string                       `json:"mongoVersion,omitempty"`
+	MongoImage         string                       `json:"mongoImage,omitempty"`
+	Message            string                       `json:"message,omitempty"`
+	Conditions         []ClusterCondition           `json:"conditions,omitempty"`
+	Replsets           map[string]*ReplsetStatus    `json:"replsets,omitempty"`
+	Mongos             *MongosStatus                `json:"mongos,omitempty"`
+	ObservedGeneration int64                        `json:"observedGeneration,omitempty"`
+	BackupStatus       AppState                     `json:"backup,omitempty"`
+	BackupVersion      string                       `json:"backupVersion,omitempty"`
+	PMMStatus          AppState                     `json:"pmmStatus,omitempty"`
+	PMMVersion         string                       `json:"pmmVersion,omitempty"`
+	BackupTasks        map[string]*BackupTaskStatus `json:"backupTasks,omitempty"`
 }
 
 type ConditionStatus string
@@ -138,11 +254,16 @@ const (
 type ClusterConditionType string
 
 const (
-	ClusterReady   ClusterConditionType = "ClusterReady"
-	ClusterInit    ClusterConditionType = "ClusterInitializing"
-	ClusterRSInit  ClusterConditionType = "ReplsetInitialized"
-	ClusterRSReady ClusterConditionType = "ReplsetReady"
-	ClusterError   ClusterConditionType = "Error"
+	ClusterReady      ClusterConditionType = "ClusterReady"
+	ClusterInit       ClusterConditionType = "ClusterInitializing"
+	ClusterRSInit     ClusterConditionType = "ReplsetInitialized"
+	ClusterRSReady    ClusterConditionType = "ReplsetReady"
+	ClusterShardAdded ClusterConditionType = "ShardAdded"
+	ClusterError      ClusterConditionType = "Error"
+	ClusterPaused     ClusterConditionType = "Paused"
+	ClusterLagging    ClusterConditionType = "ReplicationLagging"
+	// ClusterVolumesResizing is the state of the data volumes expansion
+	ClusterVolumesResizing ClusterConditionType = "VolumesResizing"
 )
 
 type ClusterCondition struct {
@@ -186,7 +307,11 @@ type ReplsetSpec struct {
 	Size                     int32                      `json:"size"`
 	ClusterRole              ClusterRole                `json:"clusterRole,omitempty"`
 	Arbiter                  Arbiter                    `json:"arbiter,omitempty"`
+	Hidden                   *MemberPool                `json:"hidden,omitempty"`
+	Delayed                  *MemberPool                `json:"delayed,omitempty"`
 	Expose                   Expose                     `json:"expose,omitempty"`
+	RoleServices             RoleServices               `json:"roleServices,omitempty"`
+	ReplsetConfig            *ReplsetConfig             `json:"replsetConfig,omitempty"`
 	VolumeSpec               *VolumeSpec                `json:"volumeSpec,omitempty"`
 	ReadinessProbe           *corev1.Probe              `json:"readinessProbe,omitempty"`
 	LivenessProbe            *LivenessProbeExtended     `json:"livenessProbe,omitempty"`
@@ -226,6 +351,21 @@ type VolumeSpec struct {
 	// It has the highest level of precedence, followed by HostPath and
 	// EmptyDir. And represents the PVC specification.
 	PersistentVolumeClaim *corev1.PersistentVolumeClaimSpec `json:"persistentVolumeClaim,omitempty"`
+
+	// StorageAutoscaling grows the PersistentVolumeClaim
+	// once the members' disk usage crosses the threshold.
+	StorageAutoscaling *StorageAutoscaling `json:"storageAutoscaling,omitempty"`
+}
+
+type StorageAutoscaling struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// ThresholdPercent is the usage of the members' filesystem
+	// the volumes are grown at
+	ThresholdPercent int `json:"thresholdPercent,omitempty"`
+	// GrowthStep is how much storage is added to the volumes at once
+	GrowthStep resource.Quantity `json:"growthStep,omitempty"`
+	// MaxSize is the cap the volumes aren't grown beyond
+	MaxSize resource.Quantity `json:"maxSize,omitempty"`
 }
 
 type ResourceSpecRequirements struct {
@@ -244,10 +384,22 @@ type SecretsSpec struct {
 	SSLInternal string `json:"sslInternal,omitempty"`
 }
 
+type Sharding struct {
+	Enabled bool        `json:"enabled"`
+	Mongos  *MongosSpec `json:"mongos,omitempty"`
+}
+
 type MongosSpec struct {
-	*ResourcesSpec `json:"resources,omitempty"`
-	Port           int32 `json:"port,omitempty"`
-	HostPort       int32 `json:"hostPort,omitempty"`
+	*ResourcesSpec           `json:"resources,omitempty"`
+	Port                     int32                      `json:"port,omitempty"`
+	HostPort                 int32                      `json:"hostPort,omitempty"`
+	Size                     int32                      `json:"size,omitempty"`
+	Expose                   Expose                     `json:"expose,omitempty"`
+	ReadinessProbe           *corev1.Probe              `json:"readinessProbe,omitempty"`
+	LivenessProbe            *corev1.Probe              `json:"livenessProbe,omitempty"`
+	PodSecurityContext       *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	ContainerSecurityContext *corev1.SecurityContext    `json:"containerSecurityContext,omitempty"`
+	MultiAZ
 }
 
 type MongodSpec struct {
@@ -388,6 +540,20 @@ type BackupTaskSpec struct {
 	Schedule        string              `json:"schedule,omitempty"`
 	StorageName     string              `json:"storageName,omitempty"`
 	CompressionType pbm.CompressionType `json:"compressionType,omitempty"`
+	Keep            int                 `json:"keep,omitempty"`
+	MaxAge          *metav1.Duration    `json:"maxAge,omitempty"`
+}
+
+// HasRetention returns true if old backups of the task should be deleted
+func (t BackupTaskSpec) HasRetention() bool {
+	return t.Keep > 0 || t.MaxAge != nil
+}
+
+type BackupTaskStatus struct {
+	LastScheduled *metav1.Time `json:"lastScheduled,omitempty"`
+	NextScheduled *metav1.Time `json:"nextScheduled,omitempty"`
+	Pruned        int          `json:"pruned,omitempty"`
+	LastPruned    *metav1.Time `json:"lastPruned,omitempty"`
 }
 
 type BackupStorageS3Spec struct {
@@ -403,11 +569,49 @@ type BackupStorageType string
 const (
 	BackupStorageFilesystem BackupStorageType = "filesystem"
 	BackupStorageS3         BackupStorageType = "s3"
+	BackupStorageAzure      BackupStorageType = "azure"
+	BackupStorageGCS        BackupStorageType = "gcs"
 )
 
+// OperatorAccessible returns whether the operator can reach the storage itself.
+// Filesystem storages are mounted only into backup agents, so backups can't be
+// deleted from them, as the agents of the bundled PBM v1.2.0 can't do it.
+func (t BackupStorageType) OperatorAccessible() bool {
+	return t == BackupStorageS3
+}
+
+// BackupStorageAzureSpec is an Azure Blob storage container. The credentials
+// secret should contain AZURE_STORAGE_ACCOUNT_NAME and AZURE_STORAGE_ACCOUNT_KEY
+type BackupStorageAzureSpec struct {
+	Container         string `json:"container"`
+	Prefix            string `json:"prefix,omitempty"`
+	EndpointURL       string `json:"endpointUrl,omitempty"`
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// BackupStorageGCSSpec is a Google Cloud Storage bucket. The credentials
+// secret should contain GCS_CLIENT_EMAIL and GCS_PRIVATE_KEY of the service account
+type BackupStorageGCSSpec struct {
+	Bucket            string `json:"bucket"`
+	Prefix            string `json:"prefix,omitempty"`
+	EndpointURL       string `json:"endpointUrl,omitempty"`
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// BackupStorageFilesystemSpec is a volume mounted into backup agents
+// by the same path on each node
+type BackupStorageFilesystemSpec struct {
+	Path                  string                                    `json:"path,omitempty"`
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+	NFS                   *corev1.NFSVolumeSource                   `json:"nfs,omitempty"`
+}
+
 type BackupStorageSpec struct {
-	Type BackupStorageType   `json:"type"`
-	S3   BackupStorageS3Spec `json:"s3,omitempty"`
+	Type       BackupStorageType            `json:"type"`
+	S3         BackupStorageS3Spec          `json:"s3,omitempty"`
+	Filesystem *BackupStorageFilesystemSpec `json:"filesystem,omitempty"`
+	Azure      *BackupStorageAzureSpec      `json:"azure,omitempty"`
+	GCS        *BackupStorageGCSSpec        `json:"gcs,omitempty"`
 }
 
 type BackupSpec struct {
@@ -427,11 +631,73 @@ type Arbiter struct {
 	MultiAZ
 }
 
+const (
+	MemberPoolHidden  = "hidden"
+	MemberPoolDelayed = "delayed"
+)
+
+// MemberPools are the names of the replset's member pools
+var MemberPools = []string{MemberPoolHidden, MemberPoolDelayed}
+
+// MemberPool is a group of the replset's hidden members with their own
+// StatefulSet. Its members never become primary and don't vote.
+type MemberPool struct {
+	Size int32 `json:"size"`
+	// DelaySecs is the replication delay of the delayed members
+	DelaySecs  int64          `json:"delaySecs,omitempty"`
+	Resources  *ResourcesSpec `json:"resources,omitempty"`
+	VolumeSpec *VolumeSpec    `json:"volumeSpec,omitempty"`
+	MultiAZ
+}
+
+// MemberPool returns the replset's pool with the given name or nil if it isn't set
+func (rs *ReplsetSpec) MemberPool(name string) *MemberPool {
+	switch name {
+	case MemberPoolHidden:
+		return rs.Hidden
+	case MemberPoolDelayed:
+		return rs.Delayed
+	default:
+		return nil
+	}
+}
+
 type Expose struct {
 	Enabled    bool               `json:"enabled"`
 	ExposeType corev1.ServiceType `json:"exposeType,omitempty"`
 }
 
+// ReplsetConfig is the part of the replset configuration kept by the operator.
+// The unset options are left as they are in the replset.
+type ReplsetConfig struct {
+	ElectionTimeoutMillis int64                     `json:"electionTimeoutMillis,omitempty"`
+	ChainingAllowed       *bool                     `json:"chainingAllowed,omitempty"`
+	HeartbeatTimeoutSecs  int                       `json:"heartbeatTimeoutSecs,omitempty"`
+	GetLastErrorModes     map[string]map[string]int `json:"getLastErrorModes,omitempty"`
+	// Tags are set on every data-bearing member
+	Tags map[string]string `json:"tags,omitempty"`
+	// NodeLabelTags maps the members' tags to the labels of the nodes
+	// their pods run on, e.g. "zone: topology.kubernetes.io/zone"
+	NodeLabelTags map[string]string `json:"nodeLabelTags,omitempty"`
+	// Priorities are the rules of the members' election priority.
+	// The first matching rule is applied, the priority is 1 otherwise.
+	Priorities []MemberPriority `json:"priorities,omitempty"`
+}
+
+// MemberPriority is the election priority of the members
+// matched by the pod name and the labels of the pod's node
+type MemberPriority struct {
+	Pod          string            `json:"pod,omitempty"`
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	Priority     int               `json:"priority"`
+}
+
+// RoleServices are the ClusterIP services selecting
+// the replset's primary and secondaries by the pods' role label
+type RoleServices struct {
+	Enabled bool `json:"enabled"`
+}
+
 // ServerVersion represents info about k8s / openshift server version
 type ServerVersion struct {
 	Platform version.Platform
@@ -456,10 +722,10 @@ func (cr *PerconaServerMongoDB) OwnerRef(scheme *runtime.Scheme) (metav1.OwnerRe
 	}, nil
 }
 
-// setVersion sets the API version of a PSMDB resource.
+// SetVersion sets the API version of a PSMDB resource.
 // The new (semver-matching) version is determined either by the CR's API version or an API version specified via the CR's annotations.
 // If the CR's API version is an empty string, it returns "v1"
-func (cr *PerconaServerMongoDB) setVersion() error {
+func (cr *PerconaServerMongoDB) SetVersion() error {
 	if len(cr.Spec.CRVersion) > 0 {
 		return nil
 	}
@@ -482,13 +748,25 @@ func (cr *PerconaServerMongoDB) setVersion() error {
 	return nil
 }
 
+// ConfigsvrReplset returns the config server replset of the sharded cluster
+// or nil if there is no such replset
+func (cr *PerconaServerMongoDB) ConfigsvrReplset() *ReplsetSpec {
+	for _, rs := range cr.Spec.Replsets {
+		if rs.ClusterRole == ClusterRoleConfigSvr {
+			return rs
+		}
+	}
+
+	return nil
+}
+
 func (cr *PerconaServerMongoDB) Version() *v.Version {
 	return v.Must(v.NewVersion(cr.Spec.CRVersion))
 }
 
 func (cr *PerconaServerMongoDB) CompareVersion(version string) int {
 	if len(cr.Spec.CRVersion) == 0 {
-		cr.setVersion()
+		cr.SetVersion()
 	}
 
 	//using Must because "version" must be right format
@@ -7,6 +7,8 @@ package v1
 import (
 	version "github.com/percona/percona-server-mongodb-operator/version"
 	corev1 "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -28,6 +30,22 @@ func (in *Arbiter) DeepCopy() *Arbiter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupReplsetStatus) DeepCopyInto(out *BackupReplsetStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupReplsetStatus.
+func (in *BackupReplsetStatus) DeepCopy() *BackupReplsetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupReplsetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 	*out = *in
@@ -35,13 +53,15 @@ func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 		in, out := &in.Storages, &out.Storages
 		*out = make(map[string]BackupStorageSpec, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 	if in.Tasks != nil {
 		in, out := &in.Tasks, &out.Tasks
 		*out = make([]BackupTaskSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.PodSecurityContext != nil {
 		in, out := &in.PodSecurityContext, &out.PodSecurityContext
@@ -71,6 +91,64 @@ func (in *BackupSpec) DeepCopy() *BackupSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageAzureSpec) DeepCopyInto(out *BackupStorageAzureSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageAzureSpec.
+func (in *BackupStorageAzureSpec) DeepCopy() *BackupStorageAzureSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageAzureSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageFilesystemSpec) DeepCopyInto(out *BackupStorageFilesystemSpec) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(corev1.PersistentVolumeClaimVolumeSource)
+		**out = **in
+	}
+	if in.NFS != nil {
+		in, out := &in.NFS, &out.NFS
+		*out = new(corev1.NFSVolumeSource)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageFilesystemSpec.
+func (in *BackupStorageFilesystemSpec) DeepCopy() *BackupStorageFilesystemSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageFilesystemSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageGCSSpec) DeepCopyInto(out *BackupStorageGCSSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageGCSSpec.
+func (in *BackupStorageGCSSpec) DeepCopy() *BackupStorageGCSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageGCSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStorageS3Spec) DeepCopyInto(out *BackupStorageS3Spec) {
 	*out = *in
@@ -91,6 +169,21 @@ func (in *BackupStorageS3Spec) DeepCopy() *BackupStorageS3Spec {
 func (in *BackupStorageSpec) DeepCopyInto(out *BackupStorageSpec) {
 	*out = *in
 	out.S3 = in.S3
+	if in.Filesystem != nil {
+		in, out := &in.Filesystem, &out.Filesystem
+		*out = new(BackupStorageFilesystemSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		*out = new(BackupStorageAzureSpec)
+		**out = **in
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(BackupStorageGCSSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -107,6 +200,11 @@ func (in *BackupStorageSpec) DeepCopy() *BackupStorageSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupTaskSpec) DeepCopyInto(out *BackupTaskSpec) {
 	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -120,6 +218,34 @@ func (in *BackupTaskSpec) DeepCopy() *BackupTaskSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTaskStatus) DeepCopyInto(out *BackupTaskStatus) {
+	*out = *in
+	if in.LastScheduled != nil {
+		in, out := &in.LastScheduled, &out.LastScheduled
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduled != nil {
+		in, out := &in.NextScheduled, &out.NextScheduled
+		*out = (*in).DeepCopy()
+	}
+	if in.LastPruned != nil {
+		in, out := &in.LastPruned, &out.LastPruned
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTaskStatus.
+func (in *BackupTaskStatus) DeepCopy() *BackupTaskStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTaskStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterCondition) DeepCopyInto(out *ClusterCondition) {
 	*out = *in
@@ -137,6 +263,27 @@ func (in *ClusterCondition) DeepCopy() *ClusterCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionPolicy) DeepCopyInto(out *DeletionPolicy) {
+	*out = *in
+	if in.FinalBackup != nil {
+		in, out := &in.FinalBackup, &out.FinalBackup
+		*out = new(FinalBackupSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionPolicy.
+func (in *DeletionPolicy) DeepCopy() *DeletionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Expose) DeepCopyInto(out *Expose) {
 	*out = *in
@@ -153,6 +300,22 @@ func (in *Expose) DeepCopy() *Expose {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FinalBackupSpec) DeepCopyInto(out *FinalBackupSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FinalBackupSpec.
+func (in *FinalBackupSpec) DeepCopy() *FinalBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FinalBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LivenessProbeExtended) DeepCopyInto(out *LivenessProbeExtended) {
 	*out = *in
@@ -170,6 +333,56 @@ func (in *LivenessProbeExtended) DeepCopy() *LivenessProbeExtended {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemberPool) DeepCopyInto(out *MemberPool) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ResourcesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeSpec != nil {
+		in, out := &in.VolumeSpec, &out.VolumeSpec
+		*out = new(VolumeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.MultiAZ.DeepCopyInto(&out.MultiAZ)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemberPool.
+func (in *MemberPool) DeepCopy() *MemberPool {
+	if in == nil {
+		return nil
+	}
+	out := new(MemberPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemberPriority) DeepCopyInto(out *MemberPriority) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemberPriority.
+func (in *MemberPriority) DeepCopy() *MemberPriority {
+	if in == nil {
+		return nil
+	}
+	out := new(MemberPriority)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongodSpec) DeepCopyInto(out *MongodSpec) {
 	*out = *in
@@ -515,6 +728,28 @@ func (in *MongosSpec) DeepCopyInto(out *MongosSpec) {
 		*out = new(ResourcesSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	out.Expose = in.Expose
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	in.MultiAZ.DeepCopyInto(&out.MultiAZ)
 	return
 }
 
@@ -528,6 +763,22 @@ func (in *MongosSpec) DeepCopy() *MongosSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongosStatus) DeepCopyInto(out *MongosStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongosStatus.
+func (in *MongosStatus) DeepCopy() *MongosStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MongosStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultiAZ) DeepCopyInto(out *MultiAZ) {
 	*out = *in
@@ -728,6 +979,21 @@ func (in *PerconaServerMongoDBBackupStatus) DeepCopyInto(out *PerconaServerMongo
 		*out = new(BackupStorageS3Spec)
 		**out = **in
 	}
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		*out = new(BackupStorageAzureSpec)
+		**out = **in
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(BackupStorageGCSSpec)
+		**out = **in
+	}
+	if in.Replsets != nil {
+		in, out := &in.Replsets, &out.Replsets
+		*out = make([]BackupReplsetStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -862,6 +1128,11 @@ func (in *PerconaServerMongoDBRestoreStatus) DeepCopyInto(out *PerconaServerMong
 		in, out := &in.LastTransition, &out.LastTransition
 		*out = (*in).DeepCopy()
 	}
+	if in.Replsets != nil {
+		in, out := &in.Replsets, &out.Replsets
+		*out = make([]RestoreReplsetStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -904,6 +1175,7 @@ func (in *PerconaServerMongoDBSpec) DeepCopyInto(out *PerconaServerMongoDBSpec)
 			}
 		}
 	}
+	in.Sharding.DeepCopyInto(&out.Sharding)
 	if in.Secrets != nil {
 		in, out := &in.Secrets, &out.Secrets
 		*out = new(SecretsSpec)
@@ -912,6 +1184,11 @@ func (in *PerconaServerMongoDBSpec) DeepCopyInto(out *PerconaServerMongoDBSpec)
 	in.Backup.DeepCopyInto(&out.Backup)
 	in.PMM.DeepCopyInto(&out.PMM)
 	out.UpgradeOptions = in.UpgradeOptions
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(DeletionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -950,6 +1227,26 @@ func (in *PerconaServerMongoDBStatus) DeepCopyInto(out *PerconaServerMongoDBStat
 			(*out)[key] = outVal
 		}
 	}
+	if in.Mongos != nil {
+		in, out := &in.Mongos, &out.Mongos
+		*out = new(MongosStatus)
+		**out = **in
+	}
+	if in.BackupTasks != nil {
+		in, out := &in.BackupTasks, &out.BackupTasks
+		*out = make(map[string]*BackupTaskStatus, len(*in))
+		for key, val := range *in {
+			var outVal *BackupTaskStatus
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(BackupTaskStatus)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	return
 }
 
@@ -1015,9 +1312,76 @@ func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplsetConfig) DeepCopyInto(out *ReplsetConfig) {
+	*out = *in
+	if in.ChainingAllowed != nil {
+		in, out := &in.ChainingAllowed, &out.ChainingAllowed
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GetLastErrorModes != nil {
+		in, out := &in.GetLastErrorModes, &out.GetLastErrorModes
+		*out = make(map[string]map[string]int, len(*in))
+		for key, val := range *in {
+			var outVal map[string]int
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(map[string]int, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeLabelTags != nil {
+		in, out := &in.NodeLabelTags, &out.NodeLabelTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Priorities != nil {
+		in, out := &in.Priorities, &out.Priorities
+		*out = make([]MemberPriority, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplsetConfig.
+func (in *ReplsetConfig) DeepCopy() *ReplsetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplsetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplsetMemberStatus) DeepCopyInto(out *ReplsetMemberStatus) {
 	*out = *in
+	if in.OptimeDate != nil {
+		in, out := &in.OptimeDate, &out.OptimeDate
+		*out = (*in).DeepCopy()
+	}
+	if in.LastHeartbeat != nil {
+		in, out := &in.LastHeartbeat, &out.LastHeartbeat
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -1040,7 +1404,23 @@ func (in *ReplsetSpec) DeepCopyInto(out *ReplsetSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Arbiter.DeepCopyInto(&out.Arbiter)
+	if in.Hidden != nil {
+		in, out := &in.Hidden, &out.Hidden
+		*out = new(MemberPool)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Delayed != nil {
+		in, out := &in.Delayed, &out.Delayed
+		*out = new(MemberPool)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Expose = in.Expose
+	out.RoleServices = in.RoleServices
+	if in.ReplsetConfig != nil {
+		in, out := &in.ReplsetConfig, &out.ReplsetConfig
+		*out = new(ReplsetConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.VolumeSpec != nil {
 		in, out := &in.VolumeSpec, &out.VolumeSpec
 		*out = new(VolumeSpec)
@@ -1090,10 +1470,20 @@ func (in *ReplsetStatus) DeepCopyInto(out *ReplsetStatus) {
 			if (*in)[i] != nil {
 				in, out := &(*in)[i], &(*out)[i]
 				*out = new(ReplsetMemberStatus)
-				**out = **in
+				(*in).DeepCopyInto(*out)
 			}
 		}
 	}
+	if in.AddedAsShard != nil {
+		in, out := &in.AddedAsShard, &out.AddedAsShard
+		*out = new(bool)
+		**out = **in
+	}
+	if in.StorageAutoscaling != nil {
+		in, out := &in.StorageAutoscaling, &out.StorageAutoscaling
+		*out = new(StorageAutoscalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1149,6 +1539,38 @@ func (in *ResourcesSpec) DeepCopy() *ResourcesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreReplsetStatus) DeepCopyInto(out *RestoreReplsetStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreReplsetStatus.
+func (in *RestoreReplsetStatus) DeepCopy() *RestoreReplsetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreReplsetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleServices) DeepCopyInto(out *RoleServices) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleServices.
+func (in *RoleServices) DeepCopy() *RoleServices {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleServices)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretsSpec) DeepCopyInto(out *SecretsSpec) {
 	*out = *in
@@ -1182,6 +1604,94 @@ func (in *ServerVersion) DeepCopy() *ServerVersion {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Sharding) DeepCopyInto(out *Sharding) {
+	*out = *in
+	if in.Mongos != nil {
+		in, out := &in.Mongos, &out.Mongos
+		*out = new(MongosSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sharding.
+func (in *Sharding) DeepCopy() *Sharding {
+	if in == nil {
+		return nil
+	}
+	out := new(Sharding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAutoscaling) DeepCopyInto(out *StorageAutoscaling) {
+	*out = *in
+	out.GrowthStep = in.GrowthStep.DeepCopy()
+	out.MaxSize = in.MaxSize.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAutoscaling.
+func (in *StorageAutoscaling) DeepCopy() *StorageAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAutoscalingStatus) DeepCopyInto(out *StorageAutoscalingStatus) {
+	*out = *in
+	if in.Sizes != nil {
+		in, out := &in.Sizes, &out.Sizes
+		*out = make(map[string]resource.Quantity, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]StorageScalingAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAutoscalingStatus.
+func (in *StorageAutoscalingStatus) DeepCopy() *StorageAutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageAutoscalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageScalingAction) DeepCopyInto(out *StorageScalingAction) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	out.From = in.From.DeepCopy()
+	out.To = in.To.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageScalingAction.
+func (in *StorageScalingAction) DeepCopy() *StorageScalingAction {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageScalingAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpgradeOptions) DeepCopyInto(out *UpgradeOptions) {
 	*out = *in
@@ -1216,6 +1726,11 @@ func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
 		*out = new(corev1.PersistentVolumeClaimSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StorageAutoscaling != nil {
+		in, out := &in.StorageAutoscaling, &out.StorageAutoscaling
+		*out = new(StorageAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
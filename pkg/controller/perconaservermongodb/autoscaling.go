@@ -0,0 +1,219 @@
+package perconaservermongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	mgo "go.mongodb.org/mongo-driver/mongo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+// autoscaleStorage grows the data volumes of the replset's components once
+// the disk usage of any of their members crosses the threshold. The grown
+// size is kept in the status and is applied to the StatefulSet's claim
+// templates on the next reconcile, which expands the volumes.
+func (r *ReconcilePerconaServerMongoDB) autoscaleStorage(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, pods corev1.PodList, secret *corev1.Secret) error {
+	for _, component := range append([]string{"mongod"}, api.MemberPools...) {
+		volumeSpec := replset.VolumeSpec
+		if component != "mongod" {
+			pool := replset.MemberPool(component)
+			if pool == nil || pool.Size == 0 {
+				continue
+			}
+			volumeSpec = pool.VolumeSpec
+		}
+		if volumeSpec == nil || volumeSpec.PersistentVolumeClaim == nil ||
+			volumeSpec.StorageAutoscaling == nil || !volumeSpec.StorageAutoscaling.Enabled {
+			continue
+		}
+
+		err := r.autoscaleComponentStorage(cr, replset, component, volumeSpec, pods, secret)
+		if err != nil {
+			return errors.Wrapf(err, "autoscale storage of %s", component)
+		}
+	}
+
+	return nil
+}
+
+func (r *ReconcilePerconaServerMongoDB) autoscaleComponentStorage(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, component string, volumeSpec *api.VolumeSpec, pods corev1.PodList, secret *corev1.Secret) error {
+	sfsName := cr.Name + "-" + replset.Name
+	if component != "mongod" {
+		sfsName += "-" + component
+	}
+
+	sfs := &appsv1.StatefulSet{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: sfsName, Namespace: cr.Namespace}, sfs)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "get StatefulSet %s", sfsName)
+	}
+
+	current, ok := claimStorage(sfs.Spec.VolumeClaimTemplates)
+	if !ok {
+		return nil
+	}
+
+	// the volumes are still being resized
+	target := dataVolumeSize(cr, replset.Name, component, volumeSpec.PersistentVolumeClaim)
+	if target.Cmp(current) > 0 {
+		return nil
+	}
+	pvcs, err := r.dataVolumeClaims(sfs)
+	if err != nil {
+		return err
+	}
+	for _, pvc := range pvcs {
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(requested) < 0 {
+			return nil
+		}
+	}
+
+	usedPercent, member, err := r.maxStorageUsage(cr, replset, component, pods, secret)
+	if err != nil {
+		return err
+	}
+
+	as := volumeSpec.StorageAutoscaling
+	if usedPercent < as.ThresholdPercent {
+		return nil
+	}
+
+	if current.Cmp(as.MaxSize) >= 0 {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, eventStorageAutoscaleCapped, "Storage of %s is %d%% used on %s, but volumes of %s are already at the max size %s", component, usedPercent, member, sfs.Name, as.MaxSize.String())
+		return nil
+	}
+
+	next := nextStorageSize(current, as.GrowthStep, as.MaxSize)
+
+	rsStatus, ok := cr.Status.Replsets[replset.Name]
+	if !ok {
+		rsStatus = &api.ReplsetStatus{}
+		cr.Status.Replsets[replset.Name] = rsStatus
+	}
+	if rsStatus.StorageAutoscaling == nil {
+		rsStatus.StorageAutoscaling = &api.StorageAutoscalingStatus{}
+	}
+	st := rsStatus.StorageAutoscaling
+	if st.Sizes == nil {
+		st.Sizes = make(map[string]resource.Quantity)
+	}
+	st.Sizes[component] = next
+	st.Actions = append(st.Actions, api.StorageScalingAction{
+		Time:        metav1.NewTime(time.Now()),
+		Component:   component,
+		Member:      member,
+		UsedPercent: usedPercent,
+		From:        current,
+		To:          next,
+	})
+	if len(st.Actions) > maxStatusesQuantity {
+		st.Actions = st.Actions[len(st.Actions)-maxStatusesQuantity:]
+	}
+
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, eventStorageAutoscaled, "Storage of %s is %d%% used on %s, volumes of %s are grown from %s to %s", component, usedPercent, member, sfs.Name, current.String(), next.String())
+
+	return nil
+}
+
+// maxStorageUsage returns the highest filesystem usage in percents among
+// the running members of the component and the member it's reported by
+func (r *ReconcilePerconaServerMongoDB) maxStorageUsage(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, component string, pods corev1.PodList, secret *corev1.Secret) (int, string, error) {
+	username := string(secret.Data[envMongoDBClusterAdminUser])
+	password := string(secret.Data[envMongoDBClusterAdminPassword])
+
+	maxPercent, maxMember := 0, ""
+	for _, pod := range pods.Items {
+		if pod.Labels["app.kubernetes.io/component"] != component || !isPodReady(pod) || isPodLeaving(replset, pod) {
+			continue
+		}
+
+		host, err := psmdb.MongoHost(r.client, cr, replset, pod)
+		if err != nil {
+			return 0, "", errors.Wrapf(err, "get host for pod %s", pod.Name)
+		}
+
+		session, release, err := r.memberClient(cr, replset, host, username, password)
+		if err != nil {
+			return 0, "", errors.Wrapf(err, "get mongo client of %s", host)
+		}
+		stats, err := mongo.ReadDBStats(context.TODO(), session)
+		release()
+		if err != nil {
+			return 0, "", errors.Wrapf(err, "get db stats of %s", host)
+		}
+
+		if stats.FsTotalSize <= 0 {
+			continue
+		}
+		percent := int(stats.FsUsedSize / stats.FsTotalSize * 100)
+		if percent > maxPercent || maxMember == "" {
+			maxPercent, maxMember = percent, host
+		}
+	}
+
+	return maxPercent, maxMember, nil
+}
+
+// memberClient returns the pooled client connected directly to the member
+func (r *ReconcilePerconaServerMongoDB) memberClient(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, host, username, password string) (*mgo.Client, func(), error) {
+	conf := &mongo.Config{
+		ReplSetName: replset.Name,
+		Hosts:       []string{host},
+		Username:    username,
+		Password:    password,
+		Direct:      true,
+	}
+
+	if !cr.Spec.UnsafeConf {
+		tlsConf, err := r.mongoTLSConfig(cr)
+		if err != nil {
+			return nil, nil, err
+		}
+		conf.TLSConf = tlsConf
+	}
+
+	return mongo.DefaultPool.Client(clusterPoolSlot(cr, replset.Name, "member", host, username), conf)
+}
+
+// dataVolumeSize returns the storage the data volumes of the component
+// should have: the requested one or the one they were autoscaled to
+func dataVolumeSize(cr *api.PerconaServerMongoDB, replset, component string, pvc *corev1.PersistentVolumeClaimSpec) resource.Quantity {
+	size := pvc.Resources.Requests[corev1.ResourceStorage]
+
+	rs, ok := cr.Status.Replsets[replset]
+	if !ok || rs.StorageAutoscaling == nil {
+		return size
+	}
+	if scaled, ok := rs.StorageAutoscaling.Sizes[component]; ok && scaled.Cmp(size) > 0 {
+		return scaled
+	}
+
+	return size
+}
+
+// nextStorageSize returns the size the volumes are grown to by the step,
+// but not over the max size
+func nextStorageSize(current, step, max resource.Quantity) resource.Quantity {
+	next := current.DeepCopy()
+	next.Add(step)
+	if next.Cmp(max) > 0 {
+		return max.DeepCopy()
+	}
+
+	return next
+}
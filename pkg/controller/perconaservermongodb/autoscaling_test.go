@@ -0,0 +1,79 @@
+package perconaservermongodb
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+func TestNextStorageSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		step    string
+		max     string
+		want    string
+	}{
+		{"grown by the step", "10Gi", "5Gi", "100Gi", "15Gi"},
+		{"reaches the max", "95Gi", "5Gi", "100Gi", "100Gi"},
+		{"clamped to the max", "98Gi", "5Gi", "100Gi", "100Gi"},
+		{"mixed units", "1Ti", "512Gi", "2Ti", "1536Gi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextStorageSize(resource.MustParse(tt.current), resource.MustParse(tt.step), resource.MustParse(tt.max))
+			if want := resource.MustParse(tt.want); got.Cmp(want) != 0 {
+				t.Errorf("nextStorageSize() = %s, want %s", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestDataVolumeSize(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaimSpec{
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+		},
+	}
+	cluster := func(sizes map[string]string) *api.PerconaServerMongoDB {
+		cr := &api.PerconaServerMongoDB{}
+		if sizes == nil {
+			return cr
+		}
+		st := &api.StorageAutoscalingStatus{Sizes: make(map[string]resource.Quantity)}
+		for component, size := range sizes {
+			st.Sizes[component] = resource.MustParse(size)
+		}
+		cr.Status.Replsets = map[string]*api.ReplsetStatus{
+			"rs0": {StorageAutoscaling: st},
+		}
+		return cr
+	}
+
+	tests := []struct {
+		name      string
+		cr        *api.PerconaServerMongoDB
+		replset   string
+		component string
+		want      string
+	}{
+		{"no status", cluster(nil), "rs0", "mongod", "10Gi"},
+		{"autoscaled", cluster(map[string]string{"mongod": "15Gi"}), "rs0", "mongod", "15Gi"},
+		{"other component autoscaled", cluster(map[string]string{"hidden": "15Gi"}), "rs0", "mongod", "10Gi"},
+		{"other replset", cluster(map[string]string{"mongod": "15Gi"}), "rs1", "mongod", "10Gi"},
+		{"spec grown over the autoscaled size", cluster(map[string]string{"mongod": "5Gi"}), "rs0", "mongod", "10Gi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dataVolumeSize(tt.cr, tt.replset, tt.component, pvc)
+			if want := resource.MustParse(tt.want); got.Cmp(want) != 0 {
+				t.Errorf("dataVolumeSize() = %s, want %s", got.String(), want.String())
+			}
+		})
+	}
+}
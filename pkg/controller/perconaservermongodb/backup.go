@@ -3,63 +3,333 @@ package perconaservermongodb
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
 
 	batchv1b "k8s.io/api/batch/v1beta1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
 	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/backup"
 )
 
+// backupJobName returns the key of the task's job in the cron registry
+func backupJobName(cr *api.PerconaServerMongoDB, taskName string) string {
+	return "backup/" + cr.Namespace + "/" + cr.Name + "/" + taskName
+}
+
+// reconcileBackupTasks keeps jobs in the cron registry in sync with
+// the enabled backup tasks of the cluster
 func (r *ReconcilePerconaServerMongoDB) reconcileBackupTasks(cr *api.PerconaServerMongoDB) error {
+	r.crons.jobsMutex.Lock()
+	defer r.crons.jobsMutex.Unlock()
+
 	ctasks := make(map[string]struct{})
-	ls := backup.NewBackupCronJobLabels(cr.Name)
 
-	for _, task := range cr.Spec.Backup.Tasks {
-		cjob := backup.BackupCronJob(&task, cr.Name, cr.Namespace, cr.Spec.Backup, cr.Spec.ImagePullSecrets)
-		ls = cjob.ObjectMeta.Labels
-		if task.Enabled {
-			ctasks[cjob.Name] = struct{}{}
+	if cr.Spec.Backup.Enabled {
+		for _, task := range cr.Spec.Backup.Tasks {
+			if !task.Enabled || task.Schedule == "" {
+				continue
+			}
+			ctasks[backupJobName(cr, task.Name)] = struct{}{}
 
-			err := setControllerReference(cr, cjob, r.scheme)
+			next, err := r.scheduleBackupTask(cr, task)
 			if err != nil {
-				return fmt.Errorf("set owner reference for backup task %s: %v", cjob.Name, err)
+				return errors.Wrapf(err, "schedule task %s", task.Name)
 			}
 
-			err = r.client.Create(context.TODO(), cjob)
-			if err != nil && errors.IsAlreadyExists(err) {
-				err := r.client.Update(context.TODO(), cjob)
-				if err != nil {
-					return fmt.Errorf("update task %s: %v", task.Name, err)
-				}
-			} else if err != nil {
-				return fmt.Errorf("create task %s: %v", task.Name, err)
+			if cr.Status.BackupTasks == nil {
+				cr.Status.BackupTasks = make(map[string]*api.BackupTaskStatus)
+			}
+			status, ok := cr.Status.BackupTasks[task.Name]
+			if !ok {
+				status = &api.BackupTaskStatus{}
+				cr.Status.BackupTasks[task.Name] = status
 			}
+			status.NextScheduled = &metav1.Time{Time: next}
 		}
 	}
 
-	// Remove old/unused tasks
+	// Remove jobs of the disabled or deleted tasks
+	prefix := backupJobName(cr, "")
+	for name, job := range r.crons.jobs {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if _, ok := ctasks[name]; ok {
+			continue
+		}
+
+		log.Info("remove backup job", "job", name)
+		r.crons.crons.Remove(cron.EntryID(job.ID))
+		delete(r.crons.jobs, name)
+
+		if status, ok := cr.Status.BackupTasks[strings.TrimPrefix(name, prefix)]; ok {
+			status.NextScheduled = nil
+		}
+	}
+
+	// Backups used to be scheduled by CronJobs, so remove
+	// the ones left after the operator's upgrade
 	tasksList := &batchv1b.CronJobList{}
 	err := r.client.List(context.TODO(),
 		tasksList,
 		&client.ListOptions{
 			Namespace:     cr.Namespace,
-			LabelSelector: labels.SelectorFromSet(ls),
+			LabelSelector: labels.SelectorFromSet(backup.NewBackupCronJobLabels(cr.Name)),
 		},
 	)
 	if err != nil {
-		return fmt.Errorf("get backup list: %v", err)
+		return fmt.Errorf("get backup CronJobs list: %v", err)
 	}
 
 	for _, t := range tasksList.Items {
-		if _, ok := ctasks[t.Name]; !ok {
-			err := r.client.Delete(context.TODO(), &t)
-			if err != nil && !errors.IsNotFound(err) {
-				return fmt.Errorf("delete backup task %s: %v", t.Name, err)
+		err := r.client.Delete(context.TODO(), &t)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("delete backup CronJob %s: %v", t.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// scheduleBackupTask adds the task's job to the cron registry or reschedules it
+// if the schedule has changed. Returns the time of the task's next run.
+func (r *ReconcilePerconaServerMongoDB) scheduleBackupTask(cr *api.PerconaServerMongoDB, task api.BackupTaskSpec) (time.Time, error) {
+	sched, err := cron.ParseStandard(task.Schedule)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "parse schedule")
+	}
+
+	name := backupJobName(cr, task.Name)
+	job, ok := r.crons.jobs[name]
+	if ok && job.CronShedule == task.Schedule {
+		return sched.Next(time.Now()), nil
+	}
+
+	if ok {
+		log.Info(fmt.Sprintf("remove backup job %s because of new %s", job.CronShedule, task.Schedule), "task", task.Name)
+		r.crons.crons.Remove(cron.EntryID(job.ID))
+		delete(r.crons.jobs, name)
+	}
+
+	log.Info(fmt.Sprintf("add new backup job: %s", task.Schedule), "task", task.Name)
+	nn := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	taskName := task.Name
+	id := r.crons.crons.Schedule(sched, cron.FuncJob(func() {
+		r.runBackupTask(nn, taskName)
+	}))
+
+	r.crons.jobs[name] = Shedule{
+		ID:          int(id),
+		CronShedule: task.Schedule,
+	}
+
+	return sched.Next(time.Now()), nil
+}
+
+// runBackupTask creates a backup object for the scheduled task. The task is
+// looked up in the current cluster spec, so changes of its storage or
+// compression don't need the job to be rescheduled.
+func (r *ReconcilePerconaServerMongoDB) runBackupTask(nn types.NamespacedName, taskName string) {
+	cs := r.clusterSync(nn)
+	cs.statusMutex.Lock()
+	defer cs.statusMutex.Unlock()
+
+	cr := &api.PerconaServerMongoDB{}
+	err := r.client.Get(context.TODO(), nn, cr)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			// the cluster is deleted
+			cr.Name, cr.Namespace = nn.Name, nn.Namespace
+			name := backupJobName(cr, taskName)
+			r.crons.jobsMutex.Lock()
+			if job, ok := r.crons.jobs[name]; ok {
+				r.crons.crons.Remove(cron.EntryID(job.ID))
+				delete(r.crons.jobs, name)
+			}
+			r.crons.jobsMutex.Unlock()
+			return
+		}
+		log.Error(err, "failed to get CR", "task", taskName)
+		return
+	}
+
+	var task *api.BackupTaskSpec
+	for i := range cr.Spec.Backup.Tasks {
+		if cr.Spec.Backup.Tasks[i].Name == taskName {
+			task = &cr.Spec.Backup.Tasks[i]
+			break
+		}
+	}
+	if !cr.Spec.Backup.Enabled || task == nil || !task.Enabled {
+		return
+	}
+
+	// CronJobs used to forbid concurrent runs of the task,
+	// so keep that until the previous backup is finished
+	running, err := r.isTaskBackupRunning(cr, taskName)
+	if err != nil {
+		log.Error(err, "failed to check backups of task", "task", taskName)
+		return
+	}
+	if running {
+		log.Info("Scheduled backup skipped: previous backup of the task isn't finished", "task", taskName)
+		return
+	}
+
+	now := time.Now()
+	bcp := backup.NewScheduledBackup(cr, task, now)
+	err = r.client.Create(context.TODO(), bcp)
+	if err != nil {
+		log.Error(err, "failed to create scheduled backup", "task", taskName)
+		return
+	}
+	log.Info("Scheduled backup created", "task", taskName, "backup", bcp.Name)
+
+	if cr.Status.BackupTasks == nil {
+		cr.Status.BackupTasks = make(map[string]*api.BackupTaskStatus)
+	}
+	status, ok := cr.Status.BackupTasks[taskName]
+	if !ok {
+		status = &api.BackupTaskStatus{}
+		cr.Status.BackupTasks[taskName] = status
+	}
+	status.LastScheduled = &metav1.Time{Time: now}
+	r.crons.jobsMutex.Lock()
+	if job, ok := r.crons.jobs[backupJobName(cr, taskName)]; ok {
+		status.NextScheduled = &metav1.Time{Time: r.crons.crons.Entry(cron.EntryID(job.ID)).Next}
+	}
+	r.crons.jobsMutex.Unlock()
+
+	err = r.writeStatus(cr)
+	if err != nil {
+		log.Error(err, "failed to update backup task status", "task", taskName)
+	}
+}
+
+// isTaskBackupRunning checks if the task has a backup which isn't finished yet
+func (r *ReconcilePerconaServerMongoDB) isTaskBackupRunning(cr *api.PerconaServerMongoDB, taskName string) (bool, error) {
+	bcps := api.PerconaServerMongoDBBackupList{}
+	err := r.client.List(context.TODO(),
+		&bcps,
+		&client.ListOptions{
+			Namespace: cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(map[string]string{
+				"ancestor": taskName,
+				"cluster":  cr.Name,
+			}),
+		},
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "get backup list")
+	}
+
+	for _, bcp := range bcps.Items {
+		switch bcp.Status.State {
+		case api.BackupStateNew, api.BackupStateWaiting, api.BackupStateRequested, api.BackupStateRunning:
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// pruneBackups deletes backups of the tasks which are out of the task's retention policy.
+// A backup which can't be deleted is logged and skipped, so it doesn't stop the others.
+func (r *ReconcilePerconaServerMongoDB) pruneBackups(cr *api.PerconaServerMongoDB) error {
+	var pbms []*backup.PBM
+	defer func() {
+		backup.ClosePBMs(pbms)
+	}()
+
+	for _, task := range cr.Spec.Backup.Tasks {
+		if !task.HasRetention() {
+			continue
+		}
+
+		bcps := api.PerconaServerMongoDBBackupList{}
+		err := r.client.List(context.TODO(),
+			&bcps,
+			&client.ListOptions{
+				Namespace: cr.Namespace,
+				LabelSelector: labels.SelectorFromSet(map[string]string{
+					"ancestor": task.Name,
+					"cluster":  cr.Name,
+				}),
+			},
+		)
+		if err != nil {
+			log.Error(err, "failed to get backups of task", "task", task.Name)
+			continue
+		}
+
+		prune := backup.BackupsToPrune(task, bcps.Items, time.Now())
+		if len(prune) == 0 {
+			continue
+		}
+
+		if pbms == nil {
+			// deletion changes the pbm storage config
+			// so it shouldn't interfere with running jobs
+			active, err := backup.HasActiveJobs(r.client, cr.Name, cr.Namespace, backup.Job{})
+			if err != nil {
+				return errors.Wrap(err, "check for active jobs")
+			}
+			if active {
+				return nil
+			}
+
+			pbms, err = backup.NewPBMs(r.client, cr)
+			if err != nil {
+				return errors.Wrap(err, "create pbm object")
+			}
+		}
+
+		status, ok := cr.Status.BackupTasks[task.Name]
+		if !ok {
+			status = &api.BackupTaskStatus{}
+		}
+
+		for i := range prune {
+			bcp := &prune[i]
+			stg, ok := cr.Spec.Backup.Storages[bcp.Spec.StorageName]
+			if !ok {
+				log.Error(errors.Errorf("storage %s not found", bcp.Spec.StorageName), "failed to prune backup", "task", task.Name, "backup", bcp.Name)
+				continue
+			}
+
+			log.Info("Deleting backup out of retention", "task", task.Name, "backup", bcp.Name)
+			// failed backups may have not been sent to agents
+			if bcp.Status.PBMname != "" {
+				err = backup.DeleteClusterBackup(pbms, bcp.Status.PBMname, stg)
+				if err != nil {
+					log.Error(err, "failed to delete backup from storage", "task", task.Name, "backup", bcp.Name)
+					continue
+				}
+			}
+
+			err = r.client.Delete(context.TODO(), bcp)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				log.Error(err, "failed to delete backup", "task", task.Name, "backup", bcp.Name)
+				continue
 			}
+
+			status.Pruned++
+			status.LastPruned = &metav1.Time{Time: time.Now()}
+		}
+
+		if cr.Status.BackupTasks == nil {
+			cr.Status.BackupTasks = make(map[string]*api.BackupTaskStatus)
 		}
+		cr.Status.BackupTasks[task.Name] = status
 	}
 
 	return nil
@@ -0,0 +1,218 @@
+package perconaservermongodb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/backup"
+)
+
+// reconcileFinalizers adds the finalizers of the deletion protection and
+// of the deletion policy if it has anything to do before the cluster is gone,
+// and removes them once they are turned off. It should be called before
+// the defaults are set, so they aren't written to the cluster's spec.
+func (r *ReconcilePerconaServerMongoDB) reconcileFinalizers(cr *api.PerconaServerMongoDB) error {
+	finalizers := []struct {
+		name string
+		need bool
+	}{
+		{api.FinalizerDeletionProtection, cr.Spec.DeletionProtection},
+		{api.FinalizerDeletionPolicy, cr.Spec.DeletionPolicy.NeedsFinalizer()},
+	}
+
+	changed := false
+	for _, f := range finalizers {
+		if f.need == api.HasFinalizer(cr, f.name) {
+			continue
+		}
+		changed = true
+		if f.need {
+			cr.SetFinalizers(append(cr.GetFinalizers(), f.name))
+		} else {
+			api.RemoveFinalizer(cr, f.name)
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	return errors.Wrap(r.client.Update(context.TODO(), cr), "update finalizers")
+}
+
+// releaseCluster removes the operator's finalizers from the deleted cluster
+func (r *ReconcilePerconaServerMongoDB) releaseCluster(cr *api.PerconaServerMongoDB) error {
+	if !api.HasFinalizer(cr, api.FinalizerDeletionPolicy) && !api.HasFinalizer(cr, api.FinalizerDeletionProtection) {
+		return nil
+	}
+
+	api.RemoveFinalizer(cr, api.FinalizerDeletionPolicy)
+	api.RemoveFinalizer(cr, api.FinalizerDeletionProtection)
+	return errors.Wrap(r.client.Update(context.TODO(), cr), "remove finalizers")
+}
+
+// applyDeletionPolicy makes the final backup and deletes the data volumes
+// and secrets of the deleted cluster according to its policy. The finalizer
+// is removed once everything is done, so the cluster goes away. It's
+// called once the deletion protection of the cluster is turned off.
+func (r *ReconcilePerconaServerMongoDB) applyDeletionPolicy(cr *api.PerconaServerMongoDB) error {
+	if !api.HasFinalizer(cr, api.FinalizerDeletionPolicy) {
+		return r.releaseCluster(cr)
+	}
+
+	// the defaults shouldn't be written with the finalizer removal
+	orig := cr.DeepCopy()
+	err := cr.CheckNSetDefaults(r.serverVersion.Platform, log)
+	if err != nil {
+		return errors.Wrap(err, "wrong psmdb options")
+	}
+
+	policy := cr.Spec.DeletionPolicy
+	retain := false
+	if policy != nil && policy.FinalBackup != nil && policy.FinalBackup.Enabled {
+		done, ok, err := r.finalBackup(cr, policy.FinalBackup)
+		if err != nil {
+			return errors.Wrap(err, "final backup")
+		}
+		if !done {
+			return nil
+		}
+		retain = !ok
+	}
+
+	if policy != nil && !retain {
+		if policy.PVCs == api.DeletionDelete {
+			err = r.deleteDataVolumes(cr)
+			if err != nil {
+				return errors.Wrap(err, "delete data volumes")
+			}
+		}
+		if policy.Secrets == api.DeletionDelete {
+			err = r.deleteClusterSecrets(cr)
+			if err != nil {
+				return errors.Wrap(err, "delete secrets")
+			}
+		}
+	}
+
+	return r.releaseCluster(orig)
+}
+
+// finalBackup requests the backup of the deleted cluster once and returns
+// whether it's finished and whether it has succeeded
+func (r *ReconcilePerconaServerMongoDB) finalBackup(cr *api.PerconaServerMongoDB, spec *api.FinalBackupSpec) (done bool, ok bool, err error) {
+	if !cr.Spec.Backup.Enabled {
+		r.recorder.Event(cr, corev1.EventTypeWarning, eventFinalBackupFailed, "Final backup can't be made as backups are disabled, volumes and secrets are retained")
+		return true, false, nil
+	}
+
+	bcps := api.PerconaServerMongoDBBackupList{}
+	err = r.client.List(context.TODO(),
+		&bcps,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(backup.FinalBackupLabels(cr)),
+		},
+	)
+	if err != nil {
+		return false, false, errors.Wrap(err, "get final backup")
+	}
+
+	if len(bcps.Items) == 0 {
+		bcp := backup.NewFinalBackup(cr, spec)
+		err = r.client.Create(context.TODO(), bcp)
+		if err != nil {
+			return false, false, errors.Wrap(err, "create final backup")
+		}
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, eventFinalBackupStarted, "Final backup %s to storage %s requested", bcp.Name, spec.StorageName)
+		return false, false, nil
+	}
+
+	bcp := bcps.Items[0]
+	switch bcp.Status.State {
+	case api.BackupStateReady:
+		return true, true, nil
+	case api.BackupStateError:
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, eventFinalBackupFailed, "Final backup %s failed: %s, volumes and secrets are retained", bcp.Name, bcp.Status.Error)
+		return true, false, nil
+	}
+
+	return false, false, nil
+}
+
+// deleteDataVolumes deletes the data volume claims of the cluster.
+// The claims used by the pods are removed after the pods are gone.
+func (r *ReconcilePerconaServerMongoDB) deleteDataVolumes(cr *api.PerconaServerMongoDB) error {
+	list := corev1.PersistentVolumeClaimList{}
+	err := r.client.List(context.TODO(),
+		&list,
+		&client.ListOptions{
+			Namespace: cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(map[string]string{
+				"app.kubernetes.io/name":       "percona-server-mongodb",
+				"app.kubernetes.io/instance":   cr.Name,
+				"app.kubernetes.io/managed-by": "percona-server-mongodb-operator",
+				"app.kubernetes.io/part-of":    "percona-server-mongodb",
+			}),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "get PVC list")
+	}
+
+	prefix := psmdb.MongodDataVolClaimName + "-" + cr.Name + "-"
+	for i := range list.Items {
+		pvc := &list.Items[i]
+		if !strings.HasPrefix(pvc.Name, prefix) || pvc.DeletionTimestamp != nil {
+			continue
+		}
+
+		err = r.client.Delete(context.TODO(), pvc)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete PVC %s", pvc.Name)
+		}
+		log.Info("PVC deleted with the cluster", "cluster", cr.Name, "PVC", pvc.Name)
+	}
+
+	return nil
+}
+
+// deleteClusterSecrets deletes the users, TLS and key secrets of the cluster
+func (r *ReconcilePerconaServerMongoDB) deleteClusterSecrets(cr *api.PerconaServerMongoDB) error {
+	names := []string{
+		cr.Spec.Secrets.Users,
+		internalPrefix + cr.Name + "-users",
+		cr.Spec.Secrets.SSL,
+		cr.Spec.Secrets.SSLInternal,
+		cr.Name + "-mongodb-keyfile",
+	}
+	if cr.Spec.Mongod.Security != nil {
+		names = append(names, cr.Spec.Mongod.Security.EncryptionKeySecret)
+	}
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+
+		err := r.client.Delete(context.TODO(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cr.Namespace,
+			},
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete secret %s", name)
+		}
+	}
+
+	return nil
+}
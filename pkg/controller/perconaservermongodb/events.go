@@ -0,0 +1,26 @@
+package perconaservermongodb
+
+// Reasons of the events emitted for the cluster
+const (
+	eventReplsetInitialized     = "ReplsetInitialized"
+	eventReplsetInitFailed      = "ReplsetInitFailed"
+	eventMemberAdded            = "MemberAdded"
+	eventMemberRemoved          = "MemberRemoved"
+	eventStepDown               = "StepDown"
+	eventStepDownFailed         = "StepDownFailed"
+	eventTLSIssued              = "TLSIssued"
+	eventTLSIssueFailed         = "TLSIssueFailed"
+	eventUsersUpdated           = "UsersUpdated"
+	eventUsersUpdateFailed      = "UsersUpdateFailed"
+	eventClusterPaused          = "ClusterPaused"
+	eventReplsetConfigUpdated   = "ReplsetConfigUpdated"
+	eventScaleDownRefused       = "ScaleDownRefused"
+	eventVolumesResized         = "VolumesResized"
+	eventVolumesResizeFailed    = "VolumesResizeFailed"
+	eventStorageAutoscaled      = "StorageAutoscaled"
+	eventStorageAutoscaleCapped = "StorageAutoscaleCapped"
+	eventFinalBackupStarted     = "FinalBackupStarted"
+	eventFinalBackupFailed      = "FinalBackupFailed"
+	eventDeletionBlocked        = "DeletionBlocked"
+	eventScaleDownBlocked       = "ScaleDownBlocked"
+)
@@ -6,6 +6,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"strings"
 	"time"
 
 	mgo "go.mongodb.org/mongo-driver/mongo"
@@ -38,15 +39,19 @@ var errReplsetLimit = fmt.Errorf("maximum replset member (%d) count reached", mo
 func (r *ReconcilePerconaServerMongoDB) reconcileCluster(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, pods corev1.PodList, usersSecret *corev1.Secret) (mongoClusterState, error) {
 	username := string(usersSecret.Data[envMongoDBClusterAdminUser])
 	password := string(usersSecret.Data[envMongoDBClusterAdminPassword])
-	session, err := r.mongoClient(cr, replset, pods, username, password)
+	session, release, err := r.mongoClient(cr, replset, pods, username, password)
 	if err != nil {
 		// try to init replset and if succseed
 		// we'll go further on the next reconcile iteration
 		if !cr.Status.Replsets[replset.Name].Initialized {
 			err = r.handleReplsetInit(cr, replset, pods.Items)
 			if err != nil {
+				if err != errNoRunningMongodContainers {
+					r.recorder.Eventf(cr, corev1.EventTypeWarning, eventReplsetInitFailed, "Replset %s initialization failed: %v", replset.Name, err)
+				}
 				return clusterInit, errors.Wrap(err, "handleReplsetInit:")
 			}
+			r.recorder.Eventf(cr, corev1.EventTypeNormal, eventReplsetInitialized, "Replset %s initialized", replset.Name)
 			cr.Status.Replsets[replset.Name].Initialized = true
 			cr.Status.Conditions = append(cr.Status.Conditions, api.ClusterCondition{
 				Status:             api.ConditionTrue,
@@ -59,12 +64,7 @@ func (r *ReconcilePerconaServerMongoDB) reconcileCluster(cr *api.PerconaServerMo
 		return clusterError, errors.Wrap(err, "dial:")
 	}
 
-	defer func() {
-		err := session.Disconnect(context.TODO())
-		if err != nil {
-			log.Error(err, "failed to close connection")
-		}
-	}()
+	defer release()
 
 	cnf, err := mongo.ReadConfig(context.TODO(), session)
 	if err != nil {
@@ -72,6 +72,11 @@ func (r *ReconcilePerconaServerMongoDB) reconcileCluster(cr *api.PerconaServerMo
 	}
 
 	members := mongo.ConfigMembers{}
+	// the members of the pods removed on scale down
+	// aren't added back while the pods are terminating
+	newMembers := mongo.ConfigMembers{}
+	desired := make(map[string]memberConfig, len(pods.Items))
+	var externalHosts []string
 	for key, pod := range pods.Items {
 		if key >= mongo.MaxMembers {
 			err = errReplsetLimit
@@ -83,10 +88,19 @@ func (r *ReconcilePerconaServerMongoDB) reconcileCluster(cr *api.PerconaServerMo
 			return clusterError, fmt.Errorf("get host for pod %s: %v", pod.Name, err)
 		}
 
+		horizons, err := psmdb.MongoHorizons(r.client, cr, replset, pod)
+		if err != nil {
+			return clusterError, fmt.Errorf("get horizons for pod %s: %v", pod.Name, err)
+		}
+		for _, h := range horizons {
+			externalHosts = append(externalHosts, h)
+		}
+
 		member := mongo.ConfigMember{
 			ID:           key,
 			Host:         host,
 			BuildIndexes: true,
+			Horizons:     horizons,
 		}
 
 		switch pod.Labels["app.kubernetes.io/component"] {
@@ -94,12 +108,47 @@ func (r *ReconcilePerconaServerMongoDB) reconcileCluster(cr *api.PerconaServerMo
 			member.ArbiterOnly = true
 			member.Priority = 0
 		case "mongod":
-			member.Tags = mongo.ReplsetTags{
-				"serviceName": cr.Name,
+			mc, err := r.replsetMemberConfig(cr, replset.ReplsetConfig, pod)
+			if err != nil {
+				return clusterError, fmt.Errorf("get config of member %s: %v", pod.Name, err)
 			}
+			member.Tags = mc.tags
+			desired[host] = mc
+		case api.MemberPoolHidden, api.MemberPoolDelayed:
+			setPoolMember(&member, replset.MemberPool(pod.Labels["app.kubernetes.io/component"]))
 		}
 
 		members = append(members, member)
+		if !isPodLeaving(replset, pod) {
+			newMembers = append(newMembers, member)
+		}
+	}
+
+	if len(externalHosts) > 0 {
+		// clients can't connect through the external horizon
+		// until the certificate is valid for its hosts
+		covered, err := r.ensureExternalSANs(cr, externalHosts)
+		if err != nil {
+			log.Error(err, "failed to add external hosts to the certificate", "replset", replset.Name)
+		}
+		if !covered {
+			for i := range members {
+				members[i].Horizons = nil
+			}
+			for i := range newMembers {
+				newMembers[i].Horizons = nil
+			}
+		}
+	}
+
+	hosts := memberHosts(cnf.Members)
+
+	if cnf.Members.SetHorizons(members) {
+		cnf.Version++
+		err = mongo.WriteConfig(context.TODO(), session, cnf)
+		if err != nil {
+			return clusterError, errors.Wrap(err, "set horizons: write mongo config")
+		}
 	}
 
 	if cnf.Members.RemoveOld(members) {
@@ -112,7 +161,7 @@ func (r *ReconcilePerconaServerMongoDB) reconcileCluster(cr *api.PerconaServerMo
 		}
 	}
 
-	if cnf.Members.AddNew(members) {
+	if cnf.Members.AddNew(newMembers) {
 		cnf.Members.RemoveOld(members)
 		cnf.Members.SetVotes()
 
@@ -123,14 +172,53 @@ func (r *ReconcilePerconaServerMongoDB) reconcileCluster(cr *api.PerconaServerMo
 		}
 	}
 
+	if cnf.Members.SetPoolMembers(members) {
+		cnf.Version++
+		err = mongo.WriteConfig(context.TODO(), session, cnf)
+		if err != nil {
+			return clusterError, errors.Wrap(err, "update pool members: write mongo config")
+		}
+	}
+
+	if applyReplsetConfig(&cnf, replset.ReplsetConfig, desired) {
+		cnf.Version++
+		err = mongo.WriteConfig(context.TODO(), session, cnf)
+		if err != nil {
+			return clusterError, errors.Wrap(err, "apply replset config: write mongo config")
+		}
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, eventReplsetConfigUpdated, "Config of replset %s updated", replset.Name)
+	}
+
+	newHosts := memberHosts(cnf.Members)
+	for host := range hosts {
+		if _, ok := newHosts[host]; !ok {
+			r.recorder.Eventf(cr, corev1.EventTypeNormal, eventMemberRemoved, "Member %s removed from replset %s", host, replset.Name)
+		}
+	}
+	for host := range newHosts {
+		if _, ok := hosts[host]; !ok {
+			r.recorder.Eventf(cr, corev1.EventTypeNormal, eventMemberAdded, "Member %s added to replset %s", host, replset.Name)
+		}
+	}
+
 	rsStatus, err := mongo.RSStatus(context.TODO(), session)
 	if err != nil {
 		return clusterError, errors.Wrap(err, "unable to get replset members")
 	}
+	setMembersStatus(cr.Status.Replsets[replset.Name], &rsStatus)
+
+	err = r.updatePodRoles(cr, replset, pods.Items, &rsStatus)
+	if err != nil {
+		log.Error(err, "failed to update pods roles", "replset", replset.Name)
+	}
 	membersLive := 0
+	hasPrimary := false
 	for _, member := range rsStatus.Members {
 		switch member.State {
-		case mongo.MemberStatePrimary, mongo.MemberStateSecondary, mongo.MemberStateArbiter:
+		case mongo.MemberStatePrimary:
+			membersLive++
+			hasPrimary = true
+		case mongo.MemberStateSecondary, mongo.MemberStateArbiter:
 			membersLive++
 		case mongo.MemberStateStartup, mongo.MemberStateStartup2, mongo.MemberStateRecovering, mongo.MemberStateRollback:
 			return clusterInit, nil
@@ -138,16 +226,79 @@ func (r *ReconcilePerconaServerMongoDB) reconcileCluster(cr *api.PerconaServerMo
 			return clusterError, errors.Errorf("undefined state of the replset member %s: %v", member.Name, member.State)
 		}
 	}
-	if membersLive == len(pods.Items) {
+	// the replset isn't ready until the primary is elected,
+	// e.g. after all members are started back on unpause
+	if membersLive == len(pods.Items) && hasPrimary {
 		return clusterReady, nil
 	}
 	return clusterInit, nil
 }
 
-func (r *ReconcilePerconaServerMongoDB) mongoClient(cr *api.PerconaServerMongoDB, replSet *api.ReplsetSpec, pods corev1.PodList, username, password string) (*mgo.Client, error) {
+// setPoolMember makes the member a hidden one which can't
+// become primary and doesn't vote, delayed for the delayed pool
+func setPoolMember(member *mongo.ConfigMember, pool *api.MemberPool) {
+	member.Hidden = true
+	member.Priority = 0
+	member.Votes = 0
+	if pool != nil {
+		member.SlaveDelay = pool.DelaySecs
+	}
+}
+
+// clusterPoolSlot returns the slot of the cluster's connection in the pool
+func clusterPoolSlot(cr *api.PerconaServerMongoDB, parts ...string) string {
+	return cr.Namespace + "/" + cr.Name + "/" + strings.Join(parts, "/")
+}
+
+// setMembersStatus fills the replset status with the state of its members.
+// The replication lag of the secondaries is counted from the primary's optime.
+func setMembersStatus(status *api.ReplsetStatus, rsStatus *mongo.Status) {
+	primary := rsStatus.Primary()
+	status.Primary = ""
+	if primary != nil {
+		status.Primary = primary.Name
+	}
+
+	members := make([]*api.ReplsetMemberStatus, 0, len(rsStatus.Members))
+	for _, m := range rsStatus.Members {
+		ms := &api.ReplsetMemberStatus{
+			Name:          m.Name,
+			State:         m.StateStr,
+			Healthy:       m.Health == mongo.MemberHealthUp,
+			SyncSource:    m.SyncingTo,
+			UptimeSeconds: m.Uptime,
+		}
+		if ms.State == "" {
+			ms.State = mongo.MemberStateStrings[m.State]
+		}
+		if m.State != mongo.MemberStateArbiter && !m.OptimeDate.IsZero() {
+			ms.OptimeDate = &metav1.Time{Time: m.OptimeDate}
+		}
+		if !m.LastHeartbeat.IsZero() {
+			ms.LastHeartbeat = &metav1.Time{Time: m.LastHeartbeat}
+		}
+		if primary != nil && m.State == mongo.MemberStateSecondary {
+			lag := int64(primary.OptimeDate.Sub(m.OptimeDate).Seconds())
+			if lag > 0 {
+				ms.LagSeconds = lag
+			}
+		}
+		for _, old := range status.Members {
+			if old.Name == m.Name {
+				ms.Version = old.Version
+			}
+		}
+		members = append(members, ms)
+	}
+	status.Members = members
+}
+
+// mongoClient returns the pooled client of the replset. The release func
+// should be called once the client isn't used anymore instead of disconnecting it.
+func (r *ReconcilePerconaServerMongoDB) mongoClient(cr *api.PerconaServerMongoDB, replSet *api.ReplsetSpec, pods corev1.PodList, username, password string) (*mgo.Client, func(), error) {
 	rsAddrs, err := psmdb.GetReplsetAddrs(r.client, cr, replSet, pods.Items)
 	if err != nil {
-		return nil, errors.Wrap(err, "get replset addr")
+		return nil, nil, errors.Wrap(err, "get replset addr")
 	}
 
 	conf := &mongo.Config{
@@ -158,31 +309,58 @@ func (r *ReconcilePerconaServerMongoDB) mongoClient(cr *api.PerconaServerMongoDB
 	}
 
 	if !cr.Spec.UnsafeConf {
-		certSecret := &corev1.Secret{}
-		err := r.client.Get(context.TODO(), types.NamespacedName{
-			Name:      cr.Spec.Secrets.SSL,
-			Namespace: cr.Namespace,
-		}, certSecret)
+		conf.TLSConf, err = r.mongoTLSConfig(cr)
 		if err != nil {
-			return nil, errors.Wrap(err, "get ssl certSecret")
+			return nil, nil, err
 		}
-		pool := x509.NewCertPool()
-		pool.AppendCertsFromPEM(certSecret.Data["ca.crt"])
+	}
 
-		var clientCerts []tls.Certificate
-		cert, err := tls.X509KeyPair(certSecret.Data["tls.crt"], certSecret.Data["tls.key"])
+	return mongo.DefaultPool.Client(clusterPoolSlot(cr, replSet.Name, username), conf)
+}
+
+// mongosClient returns the pooled client of mongos
+func (r *ReconcilePerconaServerMongoDB) mongosClient(cr *api.PerconaServerMongoDB, username, password string) (*mgo.Client, func(), error) {
+	conf := &mongo.Config{
+		Hosts:    []string{psmdb.MongosHost(cr)},
+		Username: username,
+		Password: password,
+	}
+
+	if !cr.Spec.UnsafeConf {
+		tlsConf, err := r.mongoTLSConfig(cr)
 		if err != nil {
-			return nil, errors.Wrap(err, "load keypair")
-		}
-		clientCerts = append(clientCerts, cert)
-		conf.TLSConf = &tls.Config{
-			InsecureSkipVerify: true,
-			RootCAs:            pool,
-			Certificates:       clientCerts,
+			return nil, nil, err
 		}
+		conf.TLSConf = tlsConf
 	}
 
-	return mongo.Dial(conf)
+	return mongo.DefaultPool.Client(clusterPoolSlot(cr, "mongos", username), conf)
+}
+
+func (r *ReconcilePerconaServerMongoDB) mongoTLSConfig(cr *api.PerconaServerMongoDB) (*tls.Config, error) {
+	certSecret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{
+		Name:      cr.Spec.Secrets.SSL,
+		Namespace: cr.Namespace,
+	}, certSecret)
+	if err != nil {
+		return nil, errors.Wrap(err, "get ssl certSecret")
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certSecret.Data["ca.crt"])
+
+	var clientCerts []tls.Certificate
+	cert, err := tls.X509KeyPair(certSecret.Data["tls.crt"], certSecret.Data["tls.key"])
+	if err != nil {
+		return nil, errors.Wrap(err, "load keypair")
+	}
+	clientCerts = append(clientCerts, cert)
+
+	return &tls.Config{
+		InsecureSkipVerify: true,
+		RootCAs:            pool,
+		Certificates:       clientCerts,
+	}, nil
 }
 
 var errNoRunningMongodContainers = errors.New("no mongod containers in running state")
@@ -242,15 +420,23 @@ const (
 // This must be ran from within the running container to utilise the MongoDB Localhost Exeception.
 //
 // See: https://docs.mongodb.com/manual/core/security-users/#localhost-exception
-//
 func (r *ReconcilePerconaServerMongoDB) handleReplsetInit(m *api.PerconaServerMongoDB, replset *api.ReplsetSpec, pods []corev1.Pod) error {
 	for _, pod := range pods {
+		// the replset can't be initiated by the hidden member
+		if pod.Labels["app.kubernetes.io/component"] != "mongod" {
+			continue
+		}
 		if !isMongodPod(pod) || !isContainerAndPodRunning(pod, "mongod") || !isPodReady(pod) {
 			continue
 		}
 
 		log.Info("Initiating replset", "replset", replset.Name, "pod", pod.Name)
 
+		configsvr := ""
+		if replset.ClusterRole == api.ClusterRoleConfigSvr {
+			configsvr = "configsvr: true,"
+		}
+
 		host, err := psmdb.MongoHost(r.client, m, replset, pod)
 		if err != nil {
 			return fmt.Errorf("get host for the pod %s: %v", pod.Name, err)
@@ -265,13 +451,14 @@ func (r *ReconcilePerconaServerMongoDB) handleReplsetInit(m *api.PerconaServerMo
 					{
 						_id: '%s',
 						version: 1,
+						%s
 						members: [
 							{ _id: 0, host: "%s" },
 						]
 					}
 				)
 				EOF
-			`, replset.Name, host),
+			`, replset.Name, configsvr, host),
 		}
 
 		var errb, outb bytes.Buffer
@@ -313,6 +500,16 @@ func (r *ReconcilePerconaServerMongoDB) handleReplsetInit(m *api.PerconaServerMo
 	return errNoRunningMongodContainers
 }
 
+// memberHosts returns the set of the members' hosts
+func memberHosts(members mongo.ConfigMembers) map[string]struct{} {
+	hosts := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		hosts[m.Host] = struct{}{}
+	}
+
+	return hosts
+}
+
 // isMongodPod returns a boolean reflecting if a pod
 // is running a mongod container
 func isMongodPod(pod corev1.Pod) bool {
@@ -0,0 +1,296 @@
+package perconaservermongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+func (r *ReconcilePerconaServerMongoDB) reconcileMongos(cr *api.PerconaServerMongoDB, internalKeyName string, templateAnnotations map[string]string) error {
+	if !cr.Spec.Sharding.Enabled {
+		return r.deleteMongos(cr)
+	}
+
+	deploy := psmdb.MongosDeployment(cr)
+	err := setControllerReference(cr, deploy, r.scheme)
+	if err != nil {
+		return fmt.Errorf("set owner ref for Deployment %s: %v", deploy.Name, err)
+	}
+
+	errGet := r.client.Get(context.TODO(), types.NamespacedName{Name: deploy.Name, Namespace: deploy.Namespace}, deploy)
+	if errGet != nil && !k8serrors.IsNotFound(errGet) {
+		return fmt.Errorf("get Deployment %s: %v", deploy.Name, errGet)
+	}
+
+	operatorPod, err := r.operatorPod()
+	if err != nil {
+		return fmt.Errorf("failed to get operator pod: %v", err)
+	}
+	inits := []corev1.Container{psmdb.EntrypointInitContainer(operatorPod.Spec.Containers[0].Image)}
+
+	spec, err := psmdb.MongosDeploymentSpec(cr, internalKeyName, inits)
+	if err != nil {
+		return fmt.Errorf("create Deployment.Spec %s: %v", deploy.Name, err)
+	}
+
+	spec.Template.Annotations = deploy.Spec.Template.Annotations
+	if spec.Template.Annotations == nil {
+		spec.Template.Annotations = make(map[string]string)
+	}
+	for k, v := range cr.Spec.Sharding.Mongos.MultiAZ.Annotations {
+		spec.Template.Annotations[k] = v
+	}
+	for k, v := range templateAnnotations {
+		spec.Template.Annotations[k] = v
+	}
+
+	sslHash, err := r.getTLSHash(cr, cr.Spec.Secrets.SSL)
+	if err != nil {
+		return fmt.Errorf("get secret hash error: %v", err)
+	}
+	spec.Template.Annotations["percona.com/ssl-hash"] = sslHash
+
+	sslInternalHash, err := r.getTLSHash(cr, cr.Spec.Secrets.SSLInternal)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("get secret hash error: %v", err)
+	} else if err == nil {
+		spec.Template.Annotations["percona.com/ssl-internal-hash"] = sslInternalHash
+	}
+
+	deploy.Spec = spec
+	if k8serrors.IsNotFound(errGet) {
+		err = r.client.Create(context.TODO(), deploy)
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("create Deployment %s: %v", deploy.Name, err)
+		}
+	} else {
+		err = r.reconcilePDB(cr.Spec.Sharding.Mongos.PodDisruptionBudget, psmdb.MongosLabels(cr), cr.Namespace, deploy)
+		if err != nil {
+			return fmt.Errorf("PodDisruptionBudget for %s: %v", deploy.Name, err)
+		}
+		err = r.client.Update(context.TODO(), deploy)
+		if err != nil {
+			return fmt.Errorf("update Deployment %s: %v", deploy.Name, err)
+		}
+	}
+
+	return r.reconcileMongosService(cr)
+}
+
+func (r *ReconcilePerconaServerMongoDB) reconcileMongosService(cr *api.PerconaServerMongoDB) error {
+	svc := psmdb.MongosService(cr)
+	err := setControllerReference(cr, svc, r.scheme)
+	if err != nil {
+		return fmt.Errorf("set owner ref for Service %s: %v", svc.Name, err)
+	}
+
+	current := &corev1.Service{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, current)
+	if err != nil && k8serrors.IsNotFound(err) {
+		err = r.client.Create(context.TODO(), svc)
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("create Service %s: %v", svc.Name, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("get Service %s: %v", svc.Name, err)
+	}
+
+	if current.Spec.Type == svc.Spec.Type {
+		return nil
+	}
+
+	// service type can't be changed in place with the allocated
+	// ClusterIP and node ports, so just recreate it
+	err = r.client.Delete(context.TODO(), current)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("delete Service %s: %v", svc.Name, err)
+	}
+	err = r.client.Create(context.TODO(), svc)
+	if err != nil {
+		return fmt.Errorf("create Service %s: %v", svc.Name, err)
+	}
+
+	return nil
+}
+
+func (r *ReconcilePerconaServerMongoDB) deleteMongos(cr *api.PerconaServerMongoDB) error {
+	err := r.client.Delete(context.TODO(), psmdb.MongosDeployment(cr))
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("delete mongos Deployment: %v", err)
+	}
+
+	err = r.client.Delete(context.TODO(), &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      psmdb.MongosName(cr),
+			Namespace: cr.Namespace,
+		},
+	})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("delete mongos Service: %v", err)
+	}
+
+	cr.Status.Mongos = nil
+
+	return nil
+}
+
+// handleShardsAdd registers ready shard replsets in the cluster via mongos
+func (r *ReconcilePerconaServerMongoDB) handleShardsAdd(cr *api.PerconaServerMongoDB, usersSecret *corev1.Secret) error {
+	if cr.Status.Mongos == nil || cr.Status.Mongos.Status != api.AppStateReady {
+		return nil
+	}
+
+	toAdd := []*api.ReplsetSpec{}
+	for _, rs := range cr.Spec.Replsets {
+		if rs.ClusterRole != api.ClusterRoleShardSvr {
+			continue
+		}
+		status, ok := cr.Status.Replsets[rs.Name]
+		if !ok || status.Status != api.AppStateReady {
+			continue
+		}
+		if status.AddedAsShard != nil && *status.AddedAsShard {
+			continue
+		}
+		toAdd = append(toAdd, rs)
+	}
+	if len(toAdd) == 0 {
+		return nil
+	}
+
+	username := string(usersSecret.Data[envMongoDBClusterAdminUser])
+	password := string(usersSecret.Data[envMongoDBClusterAdminPassword])
+	session, release, err := r.mongosClient(cr, username, password)
+	if err != nil {
+		return errors.Wrap(err, "connect to mongos")
+	}
+	defer release()
+
+	shards, err := mongo.ListShards(context.TODO(), session)
+	if err != nil {
+		return errors.Wrap(err, "list shards")
+	}
+
+	for _, rs := range toAdd {
+		if !hasShard(shards, rs.Name) {
+			pods := &corev1.PodList{}
+			err := r.client.List(context.TODO(),
+				pods,
+				&client.ListOptions{
+					Namespace: cr.Namespace,
+					LabelSelector: labels.SelectorFromSet(map[string]string{
+						"app.kubernetes.io/name":       "percona-server-mongodb",
+						"app.kubernetes.io/instance":   cr.Name,
+						"app.kubernetes.io/replset":    rs.Name,
+						"app.kubernetes.io/managed-by": "percona-server-mongodb-operator",
+						"app.kubernetes.io/part-of":    "percona-server-mongodb",
+						"app.kubernetes.io/component":  "mongod",
+					}),
+				},
+			)
+			if err != nil {
+				return errors.Wrapf(err, "get pods list for replset %s", rs.Name)
+			}
+
+			addrs, err := psmdb.GetReplsetAddrs(r.client, cr, rs, pods.Items)
+			if err != nil {
+				return errors.Wrapf(err, "get addresses of replset %s", rs.Name)
+			}
+
+			log.Info("Adding shard", "replset", rs.Name)
+			err = mongo.AddShard(context.TODO(), session, rs.Name, strings.Join(addrs, ","))
+			if err != nil {
+				return errors.Wrapf(err, "add shard %s", rs.Name)
+			}
+
+			cr.Status.Conditions = append(cr.Status.Conditions, api.ClusterCondition{
+				Status:             api.ConditionTrue,
+				Type:               api.ClusterShardAdded,
+				Message:            rs.Name,
+				LastTransitionTime: metav1.NewTime(time.Now()),
+			})
+		}
+
+		t := true
+		cr.Status.Replsets[rs.Name].AddedAsShard = &t
+	}
+
+	return nil
+}
+
+func hasShard(shards mongo.ShardList, name string) bool {
+	for _, s := range shards.Shards {
+		if s.ID == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *ReconcilePerconaServerMongoDB) mongosStatus(cr *api.PerconaServerMongoDB) (*api.MongosStatus, error) {
+	list := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&list,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(psmdb.MongosLabels(cr)),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get list: %v", err)
+	}
+
+	status := &api.MongosStatus{
+		Size:   cr.Spec.Sharding.Mongos.Size,
+		Status: api.AppStateInit,
+	}
+
+	for _, pod := range list.Items {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type != corev1.ContainersReady {
+				continue
+			}
+			if cond.Status == corev1.ConditionTrue {
+				status.Ready++
+			} else if cond.Status == corev1.ConditionFalse {
+				for _, cntr := range pod.Status.ContainerStatuses {
+					if cntr.State.Waiting != nil && cntr.State.Waiting.Message != "" {
+						status.Message += cntr.Name + ": " + cntr.State.Waiting.Message + "; "
+					}
+				}
+			}
+		}
+	}
+
+	if status.Size == status.Ready {
+		status.Status = api.AppStateReady
+	}
+
+	return status, nil
+}
+
+func (r *ReconcilePerconaServerMongoDB) mongosUpgradeInProgress(cr *api.PerconaServerMongoDB) (bool, error) {
+	deploy := &appsv1.Deployment{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: psmdb.MongosName(cr), Namespace: cr.Namespace}, deploy)
+	if err != nil {
+		return false, err
+	}
+
+	return deploy.Status.Replicas > deploy.Status.UpdatedReplicas, nil
+}
@@ -0,0 +1,73 @@
+package perconaservermongodb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+// stepDownForPause makes the primary of the replset step down
+// before its StatefulSet is scaled to zero on pause
+func (r *ReconcilePerconaServerMongoDB) stepDownForPause(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, pods corev1.PodList, usersSecret *corev1.Secret) error {
+	sfs := &appsv1.StatefulSet{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name + "-" + replset.Name, Namespace: cr.Namespace}, sfs)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "get StatefulSet %s", replset.Name)
+	}
+
+	// the scale down is already in progress
+	if sfs.Spec.Replicas == nil || *sfs.Spec.Replicas == 0 || len(pods.Items) == 0 {
+		return nil
+	}
+
+	username := string(usersSecret.Data[envMongoDBClusterAdminUser])
+	password := string(usersSecret.Data[envMongoDBClusterAdminPassword])
+	session, release, err := r.mongoClient(cr, replset, pods, username, password)
+	if err != nil {
+		return errors.Wrap(err, "get mongo client")
+	}
+	defer release()
+
+	err = mongo.StepDown(context.TODO(), session)
+	if err != nil {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, eventStepDownFailed, "Primary of replset %s failed to step down before pause: %v", replset.Name, err)
+		return errors.Wrap(err, "step down primary")
+	}
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, eventStepDown, "Primary of replset %s stepped down before pause", replset.Name)
+
+	return nil
+}
+
+// isPaused returns whether all pods of the cluster are gone
+func (r *ReconcilePerconaServerMongoDB) isPaused(cr *api.PerconaServerMongoDB) (bool, error) {
+	pods := &corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		pods,
+		&client.ListOptions{
+			Namespace: cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(map[string]string{
+				"app.kubernetes.io/name":       "percona-server-mongodb",
+				"app.kubernetes.io/instance":   cr.Name,
+				"app.kubernetes.io/managed-by": "percona-server-mongodb-operator",
+				"app.kubernetes.io/part-of":    "percona-server-mongodb",
+			}),
+		},
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "get pods list")
+	}
+
+	return len(pods.Items) == 0, nil
+}
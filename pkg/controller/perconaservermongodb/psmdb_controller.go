@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,6 +16,7 @@ import (
 	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
 	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb"
 	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/backup"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
 	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/secret"
 	"github.com/percona/percona-server-mongodb-operator/version"
 	"github.com/pkg/errors"
@@ -28,19 +30,30 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var secretFileMode int32 = 288
 var log = logf.Log.WithName("controller_psmdb")
-var usersSecretName string
+
+// usersSecretName returns the name of the secret with the system users
+// used by the cluster's pods
+func usersSecretName(cr *api.PerconaServerMongoDB) string {
+	if cr.CompareVersion("1.5.0") >= 0 {
+		return internalPrefix + cr.Name + "-users"
+	}
+
+	return cr.Spec.Secrets.Users
+}
 
 // Add creates a new PerconaServerMongoDB Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
@@ -67,28 +80,116 @@ func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
 		return nil, fmt.Errorf("create clientcmd: %v", err)
 	}
 
+	reconcileIn, err := reconcileInterval()
+	if err != nil {
+		return nil, err
+	}
+
 	return &ReconcilePerconaServerMongoDB{
 		client:        mgr.GetClient(),
+		apiReader:     mgr.GetAPIReader(),
 		scheme:        mgr.GetScheme(),
 		serverVersion: sv,
-		reconcileIn:   time.Second * 5,
+		reconcileIn:   reconcileIn,
 		crons:         NewCronRegistry(),
-		statusMutex:   new(sync.Mutex),
+		recorder:      mgr.GetEventRecorderFor("psmdb-controller"),
 
 		clientcmd: cli,
 	}, nil
 }
 
+// defaultReconcileInterval is the period of the cluster reconciliation
+// if RESYNC_PERIOD isn't set. The changes of the cluster's objects are
+// handled by the watches, so it's just a safety net.
+const defaultReconcileInterval = 30 * time.Second
+
+// notReadyReconcileInterval is the period of the reconciliation
+// of the cluster which isn't ready yet
+const notReadyReconcileInterval = 5 * time.Second
+
+func reconcileInterval() (time.Duration, error) {
+	v := os.Getenv("RESYNC_PERIOD")
+	if v == "" {
+		return defaultReconcileInterval, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("parse RESYNC_PERIOD %q: %v", v, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("RESYNC_PERIOD should be positive, got %s", v)
+	}
+
+	return d, nil
+}
+
+// defaultMaxConcurrentReconciles is the number of clusters reconciled
+// at the same time if MAX_CONCURRENT_RECONCILES isn't set
+const defaultMaxConcurrentReconciles = 5
+
+func maxConcurrentReconciles() (int, error) {
+	v := os.Getenv("MAX_CONCURRENT_RECONCILES")
+	if v == "" {
+		return defaultMaxConcurrentReconciles, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("parse MAX_CONCURRENT_RECONCILES %q: %v", v, err)
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("MAX_CONCURRENT_RECONCILES should be positive, got %d", n)
+	}
+
+	return n, nil
+}
+
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	maxReconciles, err := maxConcurrentReconciles()
+	if err != nil {
+		return err
+	}
+
 	// Create a new controller
-	c, err := controller.New("psmdb-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("psmdb-controller", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: maxReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource PerconaServerMongoDB. The status
+	// is updated on every reconcile (e.g. members' optimes), so its changes
+	// are skipped not to run the reconcile in a loop.
+	err = c.Watch(&source.Kind{Type: &api.PerconaServerMongoDB{}}, &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
 	if err != nil {
 		return err
 	}
 
-	// Watch for changes to primary resource PerconaServerMongoDB
-	err = c.Watch(&source.Kind{Type: &api.PerconaServerMongoDB{}}, &handler.EnqueueRequestForObject{})
+	// Watch for changes to the resources owned by the cluster
+	for _, obj := range []runtime.Object{
+		&appsv1.StatefulSet{},
+		&appsv1.Deployment{},
+		&corev1.Service{},
+		&policyv1beta1.PodDisruptionBudget{},
+	} {
+		err = c.Watch(&source.Kind{Type: obj}, &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &api.PerconaServerMongoDB{},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Watch for changes to the secrets used by the cluster. Some of them
+	// are created by the user, so they can't be mapped by the owner.
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: secretClustersMapper{client: mgr.GetClient()},
+	})
 	if err != nil {
 		return err
 	}
@@ -96,9 +197,69 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	return nil
 }
 
+// secretClustersMapper maps a secret to the clusters using it
+type secretClustersMapper struct {
+	client client.Client
+}
+
+func (m secretClustersMapper) Map(obj handler.MapObject) []reconcile.Request {
+	list := &api.PerconaServerMongoDBList{}
+	err := m.client.List(context.TODO(), list, &client.ListOptions{Namespace: obj.Meta.GetNamespace()})
+	if err != nil {
+		log.Error(err, "failed to get clusters list", "secret", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, cr := range list.Items {
+		for _, name := range clusterSecrets(&cr) {
+			if name == obj.Meta.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace},
+				})
+				break
+			}
+		}
+	}
+
+	return requests
+}
+
+// clusterSecrets returns names of the users, TLS and key secrets of the cluster
+func clusterSecrets(cr *api.PerconaServerMongoDB) []string {
+	secrets := api.SecretsSpec{}
+	if cr.Spec.Secrets != nil {
+		secrets = *cr.Spec.Secrets
+	}
+	if secrets.Users == "" {
+		secrets.Users = api.DefaultUsersSecretName
+	}
+	if secrets.SSL == "" {
+		secrets.SSL = cr.Name + "-ssl"
+	}
+	if secrets.SSLInternal == "" {
+		secrets.SSLInternal = cr.Name + "-ssl-internal"
+	}
+	encryptionKey := cr.Name + "-mongodb-encryption-key"
+	if cr.Spec.Mongod != nil && cr.Spec.Mongod.Security != nil && cr.Spec.Mongod.Security.EncryptionKeySecret != "" {
+		encryptionKey = cr.Spec.Mongod.Security.EncryptionKeySecret
+	}
+
+	return []string{
+		secrets.Users,
+		internalPrefix + cr.Name + "-users",
+		secrets.SSL,
+		secrets.SSLInternal,
+		encryptionKey,
+		cr.Name + "-mongodb-keyfile",
+	}
+}
+
 type CronRegistry struct {
 	crons *cron.Cron
 	jobs  map[string]Shedule
+	// jobsMutex guards jobs as the clusters are reconciled concurrently
+	jobsMutex *sync.Mutex
 }
 
 type Shedule struct {
@@ -108,8 +269,9 @@ type Shedule struct {
 
 func NewCronRegistry() CronRegistry {
 	c := CronRegistry{
-		crons: cron.New(),
-		jobs:  make(map[string]Shedule),
+		crons:     cron.New(),
+		jobs:      make(map[string]Shedule),
+		jobsMutex: new(sync.Mutex),
 	}
 
 	c.crons.Start()
@@ -125,14 +287,29 @@ type ReconcilePerconaServerMongoDB struct {
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
 	scheme *runtime.Scheme
+	// apiReader reads the objects which aren't cached, e.g. cluster-scoped nodes
+	apiReader client.Reader
+	recorder  record.EventRecorder
 
 	crons         CronRegistry
 	clientcmd     *clientcmd.Client
 	serverVersion *version.ServerVersion
 	reconcileIn   time.Duration
 
-	statusMutex *sync.Mutex
+	// clusters holds *clusterSync of the clusters by their namespaced names
+	clusters sync.Map
+}
+
+// clusterSync is the state of a cluster shared by its reconcile
+// and the cron jobs changing the cluster. It's kept after the cluster
+// is deleted, so the cluster recreated with the same name shares the lock
+// with the reconciles and jobs of the deleted one which are still running.
+type clusterSync struct {
+	statusMutex sync.Mutex
 	updateSync  int32
+	// missingSANs are the external hosts the certificate
+	// was warned about, so the warning isn't repeated
+	missingSANs map[string]bool
 }
 
 const (
@@ -140,6 +317,12 @@ const (
 	updateWait = 1
 )
 
+// clusterSync returns the sync state of the cluster, creating it if needed
+func (r *ReconcilePerconaServerMongoDB) clusterSync(nn types.NamespacedName) *clusterSync {
+	cs, _ := r.clusters.LoadOrStore(nn.String(), &clusterSync{missingSANs: make(map[string]bool)})
+	return cs.(*clusterSync)
+}
+
 // Reconcile reads that state of the cluster for a PerconaServerMongoDB object and makes changes based on the state read
 // and what is in the PerconaServerMongoDB.Spec
 // Note:
@@ -153,13 +336,14 @@ func (r *ReconcilePerconaServerMongoDB) Reconcile(request reconcile.Request) (re
 	}
 
 	// PerconaServerMongoDB object is also accessed and changed by a version service's cron job (that runs concurrently)
-	r.statusMutex.Lock()
-	defer r.statusMutex.Unlock()
+	cs := r.clusterSync(request.NamespacedName)
+	cs.statusMutex.Lock()
+	defer cs.statusMutex.Unlock()
 	// we have to be sure the reconcile loop will be run at least once
 	// in-between any version service jobs (hence any two vs jobs shouldn't be run sequentially).
 	// the version service job sets the state to  `updateWait` and the next job can be run only
 	// after the state was dropped to`updateDone` again
-	defer atomic.StoreInt32(&r.updateSync, updateDone)
+	defer atomic.StoreInt32(&cs.updateSync, updateDone)
 
 	// Fetch the PerconaServerMongoDB instance
 	cr := &api.PerconaServerMongoDB{}
@@ -169,20 +353,38 @@ func (r *ReconcilePerconaServerMongoDB) Reconcile(request reconcile.Request) (re
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			mongo.DefaultPool.Remove(request.Namespace + "/" + request.Name + "/")
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return rr, err
 	}
-	usersSecretName = cr.Spec.Secrets.Users
-	if cr.CompareVersion("1.5.0") >= 0 {
-		usersSecretName = internalPrefix + cr.Name + "-users"
+
+	if cr.DeletionTimestamp != nil && !cr.Spec.DeletionProtection {
+		err = r.applyDeletionPolicy(cr)
+		if err != nil {
+			return rr, errors.Wrap(err, "apply deletion policy")
+		}
+		return rr, nil
 	}
+
+	// the protected cluster is kept running until the protection is turned off
+	if cr.DeletionTimestamp != nil {
+		if api.HasFinalizer(cr, api.FinalizerDeletionProtection) {
+			r.recorder.Event(cr, corev1.EventTypeWarning, eventDeletionBlocked, "Cluster deletion is blocked, set deletionProtection to false to delete it")
+		}
+	} else {
+		err = r.reconcileFinalizers(cr)
+		if err != nil {
+			return rr, err
+		}
+	}
+
 	isClusterLive := clusterInit
 	defer func() {
 		err = r.updateStatus(cr, err, isClusterLive)
 		if err != nil {
-			reqLogger.Error(err, "failed to update cluster status", "replset", cr.Spec.Replsets[0].Name)
+			reqLogger.Error(err, "failed to update cluster status")
 		}
 	}()
 
@@ -208,13 +410,14 @@ func (r *ReconcilePerconaServerMongoDB) Reconcile(request reconcile.Request) (re
 		return reconcile.Result{}, fmt.Errorf("reconcile users secret: %v", err)
 	}
 	var sfsTemplateAnnotations map[string]string
-	if cr.CompareVersion("1.5.0") >= 0 {
+	// there are no pods to use the users and certificates while pause
+	if cr.CompareVersion("1.5.0") >= 0 && !cr.Spec.Pause {
 		sfsTemplateAnnotations, err = r.reconcileUsers(cr)
 		if err != nil {
 			return reconcile.Result{}, fmt.Errorf("failed to reconcile users: %v", err)
 		}
 	}
-	if !cr.Spec.UnsafeConf {
+	if !cr.Spec.UnsafeConf && !cr.Spec.Pause {
 		err = r.reconsileSSL(cr)
 		if err != nil {
 			err = errors.Errorf(`TLS secrets handler: "%v". Please create your TLS secret `+cr.Spec.Secrets.SSL+` manually or setup cert-manager correctly`, err)
@@ -246,7 +449,7 @@ func (r *ReconcilePerconaServerMongoDB) Reconcile(request reconcile.Request) (re
 	secrets := &corev1.Secret{}
 	err = r.client.Get(
 		context.TODO(),
-		types.NamespacedName{Name: usersSecretName, Namespace: cr.Namespace},
+		types.NamespacedName{Name: usersSecretName(cr), Namespace: cr.Namespace},
 		secrets,
 	)
 	if err != nil {
@@ -254,22 +457,13 @@ func (r *ReconcilePerconaServerMongoDB) Reconcile(request reconcile.Request) (re
 		return reconcile.Result{}, err
 	}
 
-	if cr.Spec.Backup.Enabled {
-		err = r.reconcileBackupTasks(cr)
-		if err != nil {
-			err = errors.Wrap(err, "reconcile backup tasks")
-			return reconcile.Result{}, err
-		}
+	err = r.reconcileBackupTasks(cr)
+	if err != nil {
+		err = errors.Wrap(err, "reconcile backup tasks")
+		return reconcile.Result{}, err
 	}
 
 	for i, replset := range cr.Spec.Replsets {
-		// multiple replica sets is not supported until sharding is
-		// added to the operator
-		if i > 0 {
-			reqLogger.Error(nil, "multiple replica sets is not yet supported, skipping replset %s", replset.Name)
-			continue
-		}
-
 		matchLabels := map[string]string{
 			"app.kubernetes.io/name":       "percona-server-mongodb",
 			"app.kubernetes.io/instance":   cr.Name,
@@ -291,14 +485,21 @@ func (r *ReconcilePerconaServerMongoDB) Reconcile(request reconcile.Request) (re
 			return reconcile.Result{}, err
 		}
 
-		_, err = r.reconcileStatefulSet(false, cr, replset, matchLabels, internalKey, secrets, sfsTemplateAnnotations)
+		if cr.Spec.Pause {
+			err = r.stepDownForPause(cr, replset, *pods, secrets)
+			if err != nil {
+				reqLogger.Error(err, "failed to step down primary before pause", "replset", replset.Name)
+			}
+		}
+
+		_, err = r.reconcileStatefulSet("mongod", cr, replset, matchLabels, internalKey, secrets, sfsTemplateAnnotations)
 		if err != nil {
 			err = errors.Errorf("reconcile StatefulSet for %s: %v", replset.Name, err)
 			return reconcile.Result{}, err
 		}
 
 		if replset.Arbiter.Enabled {
-			_, err := r.reconcileStatefulSet(true, cr, replset, matchLabels, internalKey, secrets, sfsTemplateAnnotations)
+			_, err := r.reconcileStatefulSet("arbiter", cr, replset, matchLabels, internalKey, secrets, sfsTemplateAnnotations)
 			if err != nil {
 				err = errors.Errorf("reconcile Arbiter StatefulSet for %s: %v", replset.Name, err)
 				return reconcile.Result{}, err
@@ -315,6 +516,31 @@ func (r *ReconcilePerconaServerMongoDB) Reconcile(request reconcile.Request) (re
 			}
 		}
 
+		for _, name := range api.MemberPools {
+			if replset.MemberPool(name) != nil {
+				// the pool's labels shouldn't get to the selectors of other StatefulSets
+				ls := make(map[string]string, len(matchLabels))
+				for k, v := range matchLabels {
+					ls[k] = v
+				}
+				_, err := r.reconcileStatefulSet(name, cr, replset, ls, internalKey, secrets, sfsTemplateAnnotations)
+				if err != nil {
+					err = errors.Errorf("reconcile %s members StatefulSet for %s: %v", name, replset.Name, err)
+					return reconcile.Result{}, err
+				}
+				continue
+			}
+
+			err := r.client.Delete(context.TODO(), psmdb.NewStatefulSet(
+				cr.Name+"-"+replset.Name+"-"+name,
+				cr.Namespace,
+			))
+			if err != nil && !k8serrors.IsNotFound(err) {
+				err = errors.Errorf("delete %s members in replset %s: %v", name, replset.Name, err)
+				return reconcile.Result{}, err
+			}
+		}
+
 		err = r.removeOudatedServices(cr, replset, pods)
 		if err != nil {
 			err = errors.Errorf("failed to remove old services of replset %s: %v", replset.Name, err)
@@ -357,21 +583,71 @@ func (r *ReconcilePerconaServerMongoDB) Reconcile(request reconcile.Request) (re
 			}
 		}
 
+		err = r.reconcileRoleServices(cr, replset)
+		if err != nil {
+			err = errors.Errorf("failed to reconcile role services of replset %s: %v", replset.Name, err)
+			return reconcile.Result{}, err
+		}
+
 		_, ok := cr.Status.Replsets[replset.Name]
 		if !ok {
 			cr.Status.Replsets[replset.Name] = &api.ReplsetStatus{}
 		}
 
-		isClusterLive, err = r.reconcileCluster(cr, replset, *pods, secrets)
+		if cr.Spec.Pause {
+			cr.Status.Replsets[replset.Name].Members = nil
+			cr.Status.Replsets[replset.Name].Primary = ""
+			isClusterLive = clusterInit
+			continue
+		}
+
+		rsState, err := r.reconcileCluster(cr, replset, *pods, secrets)
 		if err != nil {
 			reqLogger.Error(err, "failed to reconcile cluster", "replset", replset.Name)
 		}
 
+		if rsState == clusterReady {
+			err = r.autoscaleStorage(cr, replset, *pods, secrets)
+			if err != nil {
+				reqLogger.Error(err, "failed to autoscale storage", "replset", replset.Name)
+			}
+		}
+		// the states are ordered from ready to error, so the
+		// cluster gets the state of its least live replset
+		if i == 0 || rsState > isClusterLive {
+			isClusterLive = rsState
+		}
+
 		if err := r.fetchVersionFromMongo(cr, replset, *pods, secrets); err != nil {
 			return rr, errors.Wrap(err, "update CR version")
 		}
 	}
 
+	// the pods are going away, so there is nothing to connect to
+	if cr.Spec.Pause {
+		mongo.DefaultPool.Remove(clusterPoolSlot(cr))
+	}
+
+	if isClusterLive == clusterReady && cr.Spec.Backup.Enabled {
+		err = r.pruneBackups(cr)
+		if err != nil {
+			reqLogger.Error(err, "failed to prune old backups")
+		}
+	}
+
+	err = r.reconcileMongos(cr, internalKey, sfsTemplateAnnotations)
+	if err != nil {
+		err = errors.Wrap(err, "reconcile mongos")
+		return reconcile.Result{}, err
+	}
+
+	if cr.Spec.Sharding.Enabled {
+		err = r.handleShardsAdd(cr, secrets)
+		if err != nil {
+			reqLogger.Error(err, "failed to add shards")
+		}
+	}
+
 	err = r.sheduleEnsureVersion(cr, VersionServiceClient{
 		OpVersion: version.String(),
 	})
@@ -379,6 +655,12 @@ func (r *ReconcilePerconaServerMongoDB) Reconcile(request reconcile.Request) (re
 		return reconcile.Result{}, fmt.Errorf("failed to ensure version: %v", err)
 	}
 
+	// not every step of the cluster's start is visible in the watched
+	// objects (e.g. members catching up), so it's checked more often until ready
+	if isClusterLive != clusterReady && rr.RequeueAfter > notReadyReconcileInterval {
+		rr.RequeueAfter = notReadyReconcileInterval
+	}
+
 	return rr, nil
 }
 
@@ -422,20 +704,36 @@ func (r *ReconcilePerconaServerMongoDB) ensureSecurityKey(cr *api.PerconaServerM
 }
 
 // TODO: reduce cyclomatic complexity
-func (r *ReconcilePerconaServerMongoDB) reconcileStatefulSet(arbiter bool, cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, matchLabels map[string]string, internalKeyName string, secret *corev1.Secret, sfsTemplateAnnotations map[string]string) (*appsv1.StatefulSet, error) {
+// reconcileStatefulSet reconciles the StatefulSet of the replset's component:
+// "mongod", "arbiter" or one of the member pools
+func (r *ReconcilePerconaServerMongoDB) reconcileStatefulSet(component string, cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, matchLabels map[string]string, internalKeyName string, secret *corev1.Secret, sfsTemplateAnnotations map[string]string) (*appsv1.StatefulSet, error) {
 	sfsName := cr.Name + "-" + replset.Name
 	size := replset.Size
 	containerName := "mongod"
-	matchLabels["app.kubernetes.io/component"] = "mongod"
+	matchLabels["app.kubernetes.io/component"] = component
 	multiAZ := replset.MultiAZ
 	pdbspec := replset.PodDisruptionBudget
+	arbiter := component == "arbiter"
+	// the members of the pools are the replset's mongod
+	// with their own resources and volumes
+	rsSpec := replset
 	if arbiter {
 		sfsName += "-arbiter"
 		containerName += "-arbiter"
 		size = replset.Arbiter.Size
-		matchLabels["app.kubernetes.io/component"] = "arbiter"
 		multiAZ = replset.Arbiter.MultiAZ
 		pdbspec = replset.Arbiter.PodDisruptionBudget
+	} else if pool := replset.MemberPool(component); pool != nil {
+		sfsName += "-" + component
+		size = pool.Size
+		multiAZ = pool.MultiAZ
+		pdbspec = pool.PodDisruptionBudget
+
+		rsCopy := *replset
+		rsCopy.Resources = pool.Resources
+		rsCopy.VolumeSpec = pool.VolumeSpec
+		rsCopy.MultiAZ = pool.MultiAZ
+		rsSpec = &rsCopy
 	}
 
 	sfs := psmdb.NewStatefulSet(sfsName, cr.Namespace)
@@ -448,6 +746,10 @@ func (r *ReconcilePerconaServerMongoDB) reconcileStatefulSet(arbiter bool, cr *a
 	if errGet != nil && !k8serrors.IsNotFound(errGet) {
 		return nil, fmt.Errorf("get StatefulSet %s: %v", sfs.Name, err)
 	}
+	replicas := size
+	if errGet == nil && sfs.Spec.Replicas != nil {
+		replicas = *sfs.Spec.Replicas
+	}
 
 	inits := []corev1.Container{}
 	if cr.CompareVersion("1.5.0") >= 0 {
@@ -458,7 +760,7 @@ func (r *ReconcilePerconaServerMongoDB) reconcileStatefulSet(arbiter bool, cr *a
 		inits = append(inits, psmdb.EntrypointInitContainer(operatorPod.Spec.Containers[0].Image))
 	}
 
-	sfsSpec, err := psmdb.StatefulSpec(cr, replset, containerName, matchLabels, multiAZ, size, internalKeyName, inits)
+	sfsSpec, err := psmdb.StatefulSpec(cr, rsSpec, containerName, matchLabels, multiAZ, size, internalKeyName, inits)
 	if err != nil {
 		return nil, fmt.Errorf("create StatefulSet.Spec %s: %v", sfs.Name, err)
 	}
@@ -506,17 +808,23 @@ func (r *ReconcilePerconaServerMongoDB) reconcileStatefulSet(arbiter bool, cr *a
 			},
 		)
 	} else {
-		if replset.VolumeSpec.PersistentVolumeClaim != nil {
+		if rsSpec.VolumeSpec.PersistentVolumeClaim != nil {
+			// the volumes are never shrunk back after they were autoscaled
+			pvcSpec := rsSpec.VolumeSpec.PersistentVolumeClaim.DeepCopy()
+			if pvcSpec.Resources.Requests == nil {
+				pvcSpec.Resources.Requests = make(corev1.ResourceList)
+			}
+			pvcSpec.Resources.Requests[corev1.ResourceStorage] = dataVolumeSize(cr, replset.Name, component, pvcSpec)
 			sfsSpec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
-				psmdb.PersistentVolumeClaim(psmdb.MongodDataVolClaimName, cr.Namespace, replset.VolumeSpec.PersistentVolumeClaim),
+				psmdb.PersistentVolumeClaim(psmdb.MongodDataVolClaimName, cr.Namespace, pvcSpec),
 			}
 		} else {
 			sfsSpec.Template.Spec.Volumes = append(sfsSpec.Template.Spec.Volumes,
 				corev1.Volume{
 					Name: psmdb.MongodDataVolClaimName,
 					VolumeSource: corev1.VolumeSource{
-						HostPath: replset.VolumeSpec.HostPath,
-						EmptyDir: replset.VolumeSpec.EmptyDir,
+						HostPath: rsSpec.VolumeSpec.HostPath,
+						EmptyDir: rsSpec.VolumeSpec.EmptyDir,
 					},
 				},
 			)
@@ -528,11 +836,12 @@ func (r *ReconcilePerconaServerMongoDB) reconcileStatefulSet(arbiter bool, cr *a
 				return nil, fmt.Errorf("create a backup container: %v", err)
 			}
 			sfsSpec.Template.Spec.Containers = append(sfsSpec.Template.Spec.Containers, agentC)
+			sfsSpec.Template.Spec.Volumes = append(sfsSpec.Template.Spec.Volumes, backup.AgentVolumes(cr)...)
 		}
 
 		if cr.Spec.PMM.Enabled {
 			pmmsec := corev1.Secret{}
-			err := r.client.Get(context.TODO(), types.NamespacedName{Name: usersSecretName, Namespace: cr.Namespace}, &pmmsec)
+			err := r.client.Get(context.TODO(), types.NamespacedName{Name: usersSecretName(cr), Namespace: cr.Namespace}, &pmmsec)
 			if err != nil {
 				return nil, fmt.Errorf("check pmm secrets: %v", err)
 			}
@@ -541,7 +850,7 @@ func (r *ReconcilePerconaServerMongoDB) reconcileStatefulSet(arbiter bool, cr *a
 			_, okp := pmmsec.Data[psmdb.PMMPasswordKey]
 			is120 := cr.CompareVersion("1.2.0") >= 0
 
-			pmmC := psmdb.PMMContainer(cr.Spec.PMM, usersSecretName, okl && okp, cr.Name, is120)
+			pmmC := psmdb.PMMContainer(cr.Spec.PMM, usersSecretName(cr), okl && okp, cr.Name, is120)
 			if is120 {
 				res, err := psmdb.CreateResources(cr.Spec.PMM.Resources)
 				if err != nil {
@@ -589,6 +898,22 @@ func (r *ReconcilePerconaServerMongoDB) reconcileStatefulSet(arbiter bool, cr *a
 		}
 	}
 
+	if errGet == nil {
+		recreate, err := r.reconcileVolumes(cr, sfs, &sfsSpec)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile volumes of StatefulSet %s: %v", sfs.Name, err)
+		}
+		if recreate {
+			// the pods are kept running and adopted
+			// by the StatefulSet created with the new claim templates
+			err = r.client.Delete(context.TODO(), sfs, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return nil, fmt.Errorf("delete StatefulSet %s to update its claim templates: %v", sfs.Name, err)
+			}
+			return sfs, nil
+		}
+	}
+
 	sfs.Spec = sfsSpec
 	if k8serrors.IsNotFound(errGet) {
 		err = r.client.Create(context.TODO(), sfs)
@@ -600,6 +925,11 @@ func (r *ReconcilePerconaServerMongoDB) reconcileStatefulSet(arbiter bool, cr *a
 		if err != nil {
 			return nil, fmt.Errorf("PodDisruptionBudget for %s: %v", sfs.Name, err)
 		}
+		// the members leave the replset before their pods are removed
+		size, err = r.scaleDown(cr, replset, sfs, replicas, size, secret)
+		if err != nil {
+			log.Error(err, "failed to scale down", "StatefulSet", sfs.Name)
+		}
 		sfs.Spec.Replicas = &size
 		err = r.client.Update(context.TODO(), sfs)
 		if err != nil {
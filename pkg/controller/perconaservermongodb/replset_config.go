@@ -0,0 +1,140 @@
+package perconaservermongodb
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+// memberConfig is the desired tags and election priority of the member
+type memberConfig struct {
+	tags     mongo.ReplsetTags
+	priority int
+}
+
+// replsetMemberConfig returns the tags and the election priority of the pod's member.
+// The node of the pod is read only if its labels are used by the config.
+func (r *ReconcilePerconaServerMongoDB) replsetMemberConfig(cr *api.PerconaServerMongoDB, conf *api.ReplsetConfig, pod corev1.Pod) (memberConfig, error) {
+	tags := mongo.ReplsetTags{
+		"serviceName": cr.Name,
+	}
+	if conf == nil {
+		return memberConfig{tags: tags, priority: 1}, nil
+	}
+
+	for k, v := range conf.Tags {
+		tags[k] = v
+	}
+
+	var nodeLabels map[string]string
+	if needsNodeLabels(conf) && pod.Spec.NodeName != "" {
+		// nodes aren't cached as the operator watches only its namespace
+		node := &corev1.Node{}
+		err := r.apiReader.Get(context.TODO(), types.NamespacedName{Name: pod.Spec.NodeName}, node)
+		if err != nil {
+			return memberConfig{}, errors.Wrapf(err, "get node %s", pod.Spec.NodeName)
+		}
+		nodeLabels = node.Labels
+	}
+
+	for tag, label := range conf.NodeLabelTags {
+		if v, ok := nodeLabels[label]; ok {
+			tags[tag] = v
+		}
+	}
+
+	priority := 1
+	for _, p := range conf.Priorities {
+		if p.Pod != "" && p.Pod != pod.Name {
+			continue
+		}
+		if len(p.NodeSelector) > 0 && (nodeLabels == nil || !labels.SelectorFromSet(p.NodeSelector).Matches(labels.Set(nodeLabels))) {
+			continue
+		}
+		priority = p.Priority
+		break
+	}
+
+	return memberConfig{tags: tags, priority: priority}, nil
+}
+
+func needsNodeLabels(conf *api.ReplsetConfig) bool {
+	if len(conf.NodeLabelTags) > 0 {
+		return true
+	}
+	for _, p := range conf.Priorities {
+		if len(p.NodeSelector) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyReplsetConfig brings the settings of the replset and the tags and
+// priorities of its members to the desired state. The priorities and the
+// getLastErrorModes removed from the spec are reset to the defaults.
+// It returns whether anything was changed and the config should be written.
+func applyReplsetConfig(cnf *mongo.RSConfig, conf *api.ReplsetConfig, desired map[string]memberConfig) (changed bool) {
+	if conf == nil {
+		conf = &api.ReplsetConfig{}
+	}
+
+	for i := range cnf.Members {
+		member := &[]mongo.ConfigMember(cnf.Members)[i]
+		d, ok := desired[member.Host]
+		if !ok || member.ArbiterOnly {
+			continue
+		}
+
+		if !reflect.DeepEqual(member.Tags, d.tags) {
+			member.Tags = d.tags
+			changed = true
+		}
+
+		// members without a vote can't be elected,
+		// so their priority is kept at zero
+		if member.Votes > 0 && !member.Hidden && member.Priority != d.priority {
+			member.Priority = d.priority
+			changed = true
+		}
+	}
+
+	s := &cnf.Settings
+	if conf.ElectionTimeoutMillis > 0 && s.ElectionTimeoutMillis != conf.ElectionTimeoutMillis {
+		s.ElectionTimeoutMillis = conf.ElectionTimeoutMillis
+		changed = true
+	}
+	if conf.HeartbeatTimeoutSecs > 0 && s.HeartbeatTimeoutSecs != conf.HeartbeatTimeoutSecs {
+		s.HeartbeatTimeoutSecs = conf.HeartbeatTimeoutSecs
+		changed = true
+	}
+	if conf.ChainingAllowed != nil && (s.ChainingAllowed == nil || *s.ChainingAllowed != *conf.ChainingAllowed) {
+		v := *conf.ChainingAllowed
+		s.ChainingAllowed = &v
+		changed = true
+	}
+	if len(conf.GetLastErrorModes) > 0 && !reflect.DeepEqual(s.GetLastErrorModes, conf.GetLastErrorModes) {
+		s.GetLastErrorModes = make(map[string]map[string]int, len(conf.GetLastErrorModes))
+		for mode, tags := range conf.GetLastErrorModes {
+			s.GetLastErrorModes[mode] = make(map[string]int, len(tags))
+			for tag, n := range tags {
+				s.GetLastErrorModes[mode][tag] = n
+			}
+		}
+		changed = true
+	}
+	if len(conf.GetLastErrorModes) == 0 && len(s.GetLastErrorModes) > 0 {
+		s.GetLastErrorModes = nil
+		changed = true
+	}
+
+	return changed
+}
@@ -0,0 +1,127 @@
+package perconaservermongodb
+
+import (
+	"reflect"
+	"testing"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+func TestApplyReplsetConfig(t *testing.T) {
+	defaultTags := mongo.ReplsetTags{"serviceName": "my-cluster"}
+	zoneTags := mongo.ReplsetTags{"serviceName": "my-cluster", "zone": "a"}
+	modes := map[string]map[string]int{"multiZone": {"zone": 2}}
+
+	member := func(host string, votes, priority int, tags mongo.ReplsetTags) mongo.ConfigMember {
+		return mongo.ConfigMember{Host: host, Votes: votes, Priority: priority, Tags: tags}
+	}
+	config := func(modes map[string]map[string]int, members ...mongo.ConfigMember) mongo.RSConfig {
+		return mongo.RSConfig{
+			Members:  members,
+			Settings: mongo.Settings{GetLastErrorModes: modes},
+		}
+	}
+	desired := func(priority int, tags mongo.ReplsetTags) map[string]memberConfig {
+		return map[string]memberConfig{
+			"rs0-0": {tags: tags, priority: priority},
+			"rs0-1": {tags: tags, priority: 1},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		cnf         mongo.RSConfig
+		conf        *api.ReplsetConfig
+		desired     map[string]memberConfig
+		want        mongo.RSConfig
+		wantChanged bool
+	}{
+		{
+			name:    "no config",
+			cnf:     config(nil, member("rs0-0", 1, 1, defaultTags), member("rs0-1", 1, 1, defaultTags)),
+			desired: desired(1, defaultTags),
+			want:    config(nil, member("rs0-0", 1, 1, defaultTags), member("rs0-1", 1, 1, defaultTags)),
+		},
+		{
+			name: "priority set",
+			cnf:  config(nil, member("rs0-0", 1, 1, defaultTags), member("rs0-1", 1, 1, defaultTags)),
+			conf: &api.ReplsetConfig{
+				Priorities: []api.MemberPriority{{Pod: "rs0-0", Priority: 5}},
+			},
+			desired:     desired(5, defaultTags),
+			want:        config(nil, member("rs0-0", 1, 5, defaultTags), member("rs0-1", 1, 1, defaultTags)),
+			wantChanged: true,
+		},
+		{
+			name:        "priorities removed",
+			cnf:         config(nil, member("rs0-0", 1, 5, defaultTags), member("rs0-1", 1, 1, defaultTags)),
+			conf:        &api.ReplsetConfig{},
+			desired:     desired(1, defaultTags),
+			want:        config(nil, member("rs0-0", 1, 1, defaultTags), member("rs0-1", 1, 1, defaultTags)),
+			wantChanged: true,
+		},
+		{
+			name:    "priority of member without vote",
+			cnf:     config(nil, member("rs0-0", 0, 0, defaultTags), member("rs0-1", 1, 1, defaultTags)),
+			conf:    &api.ReplsetConfig{},
+			desired: desired(1, defaultTags),
+			want:    config(nil, member("rs0-0", 0, 0, defaultTags), member("rs0-1", 1, 1, defaultTags)),
+		},
+		{
+			name: "getLastErrorModes set",
+			cnf:  config(nil, member("rs0-0", 1, 1, zoneTags), member("rs0-1", 1, 1, zoneTags)),
+			conf: &api.ReplsetConfig{
+				Tags:              map[string]string{"zone": "a"},
+				GetLastErrorModes: modes,
+			},
+			desired:     desired(1, zoneTags),
+			want:        config(modes, member("rs0-0", 1, 1, zoneTags), member("rs0-1", 1, 1, zoneTags)),
+			wantChanged: true,
+		},
+		{
+			name: "getLastErrorModes removed",
+			cnf:  config(modes, member("rs0-0", 1, 1, zoneTags), member("rs0-1", 1, 1, zoneTags)),
+			conf: &api.ReplsetConfig{
+				Tags: map[string]string{"zone": "a"},
+			},
+			desired:     desired(1, zoneTags),
+			want:        config(nil, member("rs0-0", 1, 1, zoneTags), member("rs0-1", 1, 1, zoneTags)),
+			wantChanged: true,
+		},
+		{
+			name:        "config removed",
+			cnf:         config(modes, member("rs0-0", 1, 5, zoneTags), member("rs0-1", 1, 1, zoneTags)),
+			desired:     desired(1, defaultTags),
+			want:        config(nil, member("rs0-0", 1, 1, defaultTags), member("rs0-1", 1, 1, defaultTags)),
+			wantChanged: true,
+		},
+		{
+			name: "arbiter and pool members aren't changed",
+			cnf: config(nil,
+				member("rs0-0", 1, 1, defaultTags),
+				mongo.ConfigMember{Host: "rs0-arbiter-0", Votes: 1, ArbiterOnly: true},
+				mongo.ConfigMember{Host: "rs0-hidden-0", Hidden: true},
+			),
+			conf:    &api.ReplsetConfig{},
+			desired: desired(1, defaultTags),
+			want: config(nil,
+				member("rs0-0", 1, 1, defaultTags),
+				mongo.ConfigMember{Host: "rs0-arbiter-0", Votes: 1, ArbiterOnly: true},
+				mongo.ConfigMember{Host: "rs0-hidden-0", Hidden: true},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := applyReplsetConfig(&tt.cnf, tt.conf, tt.desired)
+			if changed != tt.wantChanged {
+				t.Errorf("applyReplsetConfig() changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(tt.cnf, tt.want) {
+				t.Errorf("applyReplsetConfig() config = %+v, want %+v", tt.cnf, tt.want)
+			}
+		})
+	}
+}
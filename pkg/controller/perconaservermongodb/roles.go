@@ -0,0 +1,105 @@
+package perconaservermongodb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+// memberRole returns the value of the role label of the member in the state
+func memberRole(state mongo.MemberState) string {
+	switch state {
+	case mongo.MemberStatePrimary:
+		return psmdb.RolePrimary
+	case mongo.MemberStateSecondary:
+		return psmdb.RoleSecondary
+	case mongo.MemberStateArbiter:
+		return psmdb.RoleArbiter
+	default:
+		return ""
+	}
+}
+
+// updatePodRoles keeps the role label of the replset pods in sync with the
+// state of their members, so the role services select the right pods
+func (r *ReconcilePerconaServerMongoDB) updatePodRoles(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, pods []corev1.Pod, status *mongo.Status) error {
+	roles := make(map[string]string, len(status.Members))
+	for _, m := range status.Members {
+		roles[m.Name] = memberRole(m.State)
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+
+		host, err := psmdb.MongoHost(r.client, cr, replset, *pod)
+		if err != nil {
+			return errors.Wrapf(err, "get host for pod %s", pod.Name)
+		}
+
+		role := roles[host]
+		if role == psmdb.RoleSecondary && pod.Labels["app.kubernetes.io/component"] != "mongod" {
+			role = psmdb.RoleHidden
+		}
+		if pod.Labels[psmdb.LabelRole] == role {
+			continue
+		}
+
+		orig := pod.DeepCopy()
+		if role == "" {
+			delete(pod.Labels, psmdb.LabelRole)
+		} else {
+			if pod.Labels == nil {
+				pod.Labels = make(map[string]string)
+			}
+			pod.Labels[psmdb.LabelRole] = role
+		}
+
+		err = r.client.Patch(context.TODO(), pod, client.MergeFrom(orig))
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "set role label of pod %s", pod.Name)
+		}
+	}
+
+	return nil
+}
+
+// reconcileRoleServices creates the services of the replset's primary
+// and secondaries if they're enabled, or removes them otherwise
+func (r *ReconcilePerconaServerMongoDB) reconcileRoleServices(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec) error {
+	for _, role := range []string{psmdb.RolePrimary, psmdb.RoleSecondary} {
+		svc := psmdb.RoleService(cr, replset, role)
+
+		if !replset.RoleServices.Enabled {
+			err := r.client.Delete(context.TODO(), svc)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return errors.Wrapf(err, "delete Service %s", svc.Name)
+			}
+			continue
+		}
+
+		err := setControllerReference(cr, svc, r.scheme)
+		if err != nil {
+			return errors.Wrapf(err, "set owner ref for Service %s", svc.Name)
+		}
+
+		err = r.client.Get(context.TODO(), types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, &corev1.Service{})
+		if err != nil && k8serrors.IsNotFound(err) {
+			err = r.client.Create(context.TODO(), svc)
+			if err != nil && !k8serrors.IsAlreadyExists(err) {
+				return errors.Wrapf(err, "create Service %s", svc.Name)
+			}
+		} else if err != nil {
+			return errors.Wrapf(err, "get Service %s", svc.Name)
+		}
+	}
+
+	return nil
+}
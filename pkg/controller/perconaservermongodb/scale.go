@@ -0,0 +1,222 @@
+package perconaservermongodb
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+// scaleDown removes the member of the StatefulSet's last pod from the replset
+// before the pod is removed. It returns the number of replicas the StatefulSet
+// can be shrunk to now, so the members leave one by one from the highest
+// ordinal and the primary steps down before it leaves. The pod is removed by
+// one of the next reconciles, once the new config reaches the majority.
+func (r *ReconcilePerconaServerMongoDB) scaleDown(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, sfs *appsv1.StatefulSet, replicas, size int32, secret *corev1.Secret) (int32, error) {
+	if size >= replicas || cr.Spec.Pause {
+		return size, nil
+	}
+
+	// there is no replset to reconfigure yet
+	if rs, ok := cr.Status.Replsets[replset.Name]; !ok || !rs.Initialized {
+		return size, nil
+	}
+
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace: cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(map[string]string{
+				"app.kubernetes.io/name":       "percona-server-mongodb",
+				"app.kubernetes.io/instance":   cr.Name,
+				"app.kubernetes.io/replset":    replset.Name,
+				"app.kubernetes.io/managed-by": "percona-server-mongodb-operator",
+				"app.kubernetes.io/part-of":    "percona-server-mongodb",
+			}),
+		},
+	)
+	if err != nil {
+		return replicas, errors.Wrap(err, "get pods list")
+	}
+
+	var leaving *corev1.Pod
+	for i := range pods.Items {
+		if pods.Items[i].Name == sfs.Name+"-"+strconv.Itoa(int(replicas-1)) {
+			leaving = &pods.Items[i]
+		}
+	}
+	if leaving == nil {
+		return replicas - 1, nil
+	}
+
+	host, err := psmdb.MongoHost(r.client, cr, replset, *leaving)
+	if err != nil {
+		return replicas, errors.Wrapf(err, "get host for pod %s", leaving.Name)
+	}
+
+	username := string(secret.Data[envMongoDBClusterAdminUser])
+	password := string(secret.Data[envMongoDBClusterAdminPassword])
+	session, release, err := r.mongoClient(cr, replset, pods, username, password)
+	if err != nil {
+		return replicas, errors.Wrap(err, "get mongo client")
+	}
+	defer release()
+
+	cnf, err := mongo.ReadConfig(context.TODO(), session)
+	if err != nil {
+		return replicas, errors.Wrap(err, "get mongo config")
+	}
+
+	rsStatus, err := mongo.RSStatus(context.TODO(), session)
+	if err != nil {
+		return replicas, errors.Wrap(err, "get replset status")
+	}
+
+	if _, ok := memberHosts(cnf.Members)[host]; ok {
+		if cr.Spec.DeletionProtection && leaving.Labels["app.kubernetes.io/component"] == "mongod" && size < votingMajority(cnf.Members) {
+			r.recorder.Eventf(cr, corev1.EventTypeWarning, eventScaleDownBlocked, "Replset %s can't be scaled down to %d below the voting majority of %d while deletionProtection is on", replset.Name, size, votingMajority(cnf.Members))
+			return replicas, errors.Errorf("scaling down replset %s to %d is blocked by deletion protection", replset.Name, size)
+		}
+
+		if primary := rsStatus.Primary(); primary != nil && primary.Name == host {
+			err = mongo.StepDown(context.TODO(), session)
+			if err != nil {
+				r.recorder.Eventf(cr, corev1.EventTypeWarning, eventStepDownFailed, "Primary %s failed to step down before scale down: %v", leaving.Name, err)
+				return replicas, errors.Wrap(err, "step down primary")
+			}
+			r.recorder.Eventf(cr, corev1.EventTypeNormal, eventStepDown, "Primary %s stepped down before scale down", leaving.Name)
+			// the member is removed once the new primary is elected
+			return replicas, nil
+		}
+
+		if !cr.Spec.UnsafeConf && !keepsVotingMajority(cnf.Members, &rsStatus, host) {
+			r.recorder.Eventf(cr, corev1.EventTypeWarning, eventScaleDownRefused, "Replset %s can't be scaled down to %d: the rest of the members won't have a voting majority, set allowUnsafeConfigurations=true to allow it", replset.Name, size)
+			return replicas, errors.Errorf("removing member %s leaves replset without voting majority", host)
+		}
+
+		cnf.Members.Remove(host)
+		cnf.Members.SetVotes()
+
+		cnf.Version++
+		err = mongo.WriteConfig(context.TODO(), session, cnf)
+		if err != nil {
+			return replicas, errors.Wrap(err, "remove member: write mongo config")
+		}
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, eventMemberRemoved, "Member %s removed from replset %s before scale down", host, replset.Name)
+		return replicas, nil
+	}
+
+	if !configCommitted(cnf, &rsStatus) {
+		log.Info("waiting for the replset config to reach the majority before scale down", "replset", replset.Name, "version", cnf.Version)
+		return replicas, nil
+	}
+
+	return replicas - 1, nil
+}
+
+// keepsVotingMajority returns whether the healthy voting members left after
+// the removal of the given one are the majority of the current voting members
+func keepsVotingMajority(members mongo.ConfigMembers, status *mongo.Status, host string) bool {
+	healthy := make(map[string]bool, len(status.Members))
+	for _, m := range status.Members {
+		healthy[m.Name] = m.Health == mongo.MemberHealthUp
+	}
+
+	voters, left := 0, 0
+	for _, m := range members {
+		if m.Votes == 0 {
+			continue
+		}
+		voters++
+		if m.Host != host && healthy[m.Host] {
+			left++
+		}
+	}
+
+	return left > voters/2
+}
+
+// votingMajority returns the majority of the replset's voting members
+func votingMajority(members mongo.ConfigMembers) int32 {
+	var voters int32
+	for _, m := range members {
+		if m.Votes > 0 {
+			voters++
+		}
+	}
+
+	return mongo.VotingMajority(voters)
+}
+
+// configCommitted returns whether the majority of the replset's voting
+// members have the config of the given version
+func configCommitted(cnf mongo.RSConfig, status *mongo.Status) bool {
+	voters := make(map[string]struct{}, len(cnf.Members))
+	for _, m := range cnf.Members {
+		if m.Votes > 0 {
+			voters[m.Host] = struct{}{}
+		}
+	}
+
+	reached := 0
+	for _, m := range status.Members {
+		if _, ok := voters[m.Name]; ok && m.Health == mongo.MemberHealthUp && m.ConfigVersion >= cnf.Version {
+			reached++
+		}
+	}
+
+	return reached > len(voters)/2
+}
+
+// podOrdinal returns the ordinal of the StatefulSet's pod
+func podOrdinal(pod corev1.Pod) (int, bool) {
+	i := strings.LastIndex(pod.Name, "-")
+	if i < 0 {
+		return 0, false
+	}
+	ord, err := strconv.Atoi(pod.Name[i+1:])
+	if err != nil {
+		return 0, false
+	}
+
+	return ord, true
+}
+
+// isPodLeaving returns whether the pod is deleted or is going to be removed
+// by its StatefulSet, so its member shouldn't be added to the replset
+func isPodLeaving(replset *api.ReplsetSpec, pod corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return true
+	}
+
+	ord, ok := podOrdinal(pod)
+	if !ok {
+		return false
+	}
+
+	size := replset.Size
+	switch component := pod.Labels["app.kubernetes.io/component"]; component {
+	case "arbiter":
+		size = 0
+		if replset.Arbiter.Enabled {
+			size = replset.Arbiter.Size
+		}
+	case api.MemberPoolHidden, api.MemberPoolDelayed:
+		size = 0
+		if pool := replset.MemberPool(component); pool != nil {
+			size = pool.Size
+		}
+	}
+
+	return int32(ord) >= size
+}
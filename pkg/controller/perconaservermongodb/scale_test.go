@@ -0,0 +1,212 @@
+package perconaservermongodb
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+func TestKeepsVotingMajority(t *testing.T) {
+	member := func(host string, votes int) mongo.ConfigMember {
+		return mongo.ConfigMember{Host: host, Votes: votes}
+	}
+	status := func(down ...string) *mongo.Status {
+		st := &mongo.Status{}
+		for _, h := range []string{"rs0-0", "rs0-1", "rs0-2", "rs0-3", "rs0-arbiter-0", "rs0-hidden-0"} {
+			health := mongo.MemberHealthUp
+			for _, d := range down {
+				if d == h {
+					health = mongo.MemberHealthDown
+				}
+			}
+			st.Members = append(st.Members, &mongo.Member{Name: h, Health: health})
+		}
+		return st
+	}
+
+	tests := []struct {
+		name    string
+		members mongo.ConfigMembers
+		status  *mongo.Status
+		host    string
+		want    bool
+	}{
+		{
+			name:    "3 to 2",
+			members: mongo.ConfigMembers{member("rs0-0", 1), member("rs0-1", 1), member("rs0-2", 1)},
+			status:  status(),
+			host:    "rs0-2",
+			want:    true,
+		},
+		{
+			name:    "2 to 1 removing the voter",
+			members: mongo.ConfigMembers{member("rs0-0", 1), member("rs0-1", 0)},
+			status:  status(),
+			host:    "rs0-0",
+			want:    false,
+		},
+		{
+			name:    "2 to 1 removing the member without vote",
+			members: mongo.ConfigMembers{member("rs0-0", 1), member("rs0-1", 0)},
+			status:  status(),
+			host:    "rs0-1",
+			want:    true,
+		},
+		{
+			name:    "unhealthy voter",
+			members: mongo.ConfigMembers{member("rs0-0", 1), member("rs0-1", 1), member("rs0-2", 1)},
+			status:  status("rs0-0"),
+			host:    "rs0-2",
+			want:    false,
+		},
+		{
+			name:    "unhealthy voter is removed",
+			members: mongo.ConfigMembers{member("rs0-0", 1), member("rs0-1", 1), member("rs0-2", 1)},
+			status:  status("rs0-2"),
+			host:    "rs0-2",
+			want:    true,
+		},
+		{
+			name:    "arbiter votes",
+			members: mongo.ConfigMembers{member("rs0-0", 1), member("rs0-1", 1), member("rs0-arbiter-0", 1)},
+			status:  status(),
+			host:    "rs0-1",
+			want:    true,
+		},
+		{
+			name:    "unhealthy arbiter",
+			members: mongo.ConfigMembers{member("rs0-0", 1), member("rs0-1", 1), member("rs0-arbiter-0", 1)},
+			status:  status("rs0-arbiter-0"),
+			host:    "rs0-1",
+			want:    false,
+		},
+		{
+			name:    "hidden member doesn't vote",
+			members: mongo.ConfigMembers{member("rs0-0", 1), member("rs0-1", 0), member("rs0-hidden-0", 0)},
+			status:  status(),
+			host:    "rs0-0",
+			want:    false,
+		},
+		{
+			name:    "member unknown to the status",
+			members: mongo.ConfigMembers{member("rs0-0", 1), member("rs0-1", 1), member("rs0-4", 1)},
+			status:  status(),
+			host:    "rs0-1",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keepsVotingMajority(tt.members, tt.status, tt.host); got != tt.want {
+				t.Errorf("keepsVotingMajority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPodLeaving(t *testing.T) {
+	pod := func(name, component string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"app.kubernetes.io/component": component},
+			},
+		}
+	}
+	deleted := pod("cluster-rs0-0", "mongod")
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+
+	replset := &api.ReplsetSpec{
+		Name:    "rs0",
+		Size:    3,
+		Arbiter: api.Arbiter{Enabled: true, Size: 1},
+		Hidden:  &api.MemberPool{Size: 1},
+		Delayed: &api.MemberPool{Size: 2},
+	}
+
+	tests := []struct {
+		name    string
+		replset *api.ReplsetSpec
+		pod     corev1.Pod
+		want    bool
+	}{
+		{"member", replset, pod("cluster-rs0-2", "mongod"), false},
+		{"member out of size", replset, pod("cluster-rs0-3", "mongod"), true},
+		{"deleted member", replset, deleted, true},
+		{"arbiter", replset, pod("cluster-rs0-arbiter-0", "arbiter"), false},
+		{"arbiter out of size", replset, pod("cluster-rs0-arbiter-1", "arbiter"), true},
+		{"arbiter disabled", &api.ReplsetSpec{Name: "rs0", Size: 3, Arbiter: api.Arbiter{Size: 1}}, pod("cluster-rs0-arbiter-0", "arbiter"), true},
+		{"hidden", replset, pod("cluster-rs0-hidden-0", api.MemberPoolHidden), false},
+		{"hidden out of size", replset, pod("cluster-rs0-hidden-1", api.MemberPoolHidden), true},
+		{"delayed", replset, pod("cluster-rs0-delayed-1", api.MemberPoolDelayed), false},
+		{"delayed out of size", replset, pod("cluster-rs0-delayed-2", api.MemberPoolDelayed), true},
+		{"pool removed", &api.ReplsetSpec{Name: "rs0", Size: 3}, pod("cluster-rs0-hidden-0", api.MemberPoolHidden), true},
+		{"no ordinal", replset, pod("cluster-rs0", "mongod"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPodLeaving(tt.replset, tt.pod); got != tt.want {
+				t.Errorf("isPodLeaving() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigCommitted(t *testing.T) {
+	cnf := mongo.RSConfig{
+		Version: 5,
+		Members: mongo.ConfigMembers{
+			{Host: "rs0-0", Votes: 1},
+			{Host: "rs0-1", Votes: 1},
+			{Host: "rs0-2", Votes: 1},
+			{Host: "rs0-hidden-0", Votes: 0},
+		},
+	}
+	status := func(versions ...int) *mongo.Status {
+		st := &mongo.Status{}
+		for i, h := range []string{"rs0-0", "rs0-1", "rs0-2", "rs0-hidden-0"} {
+			st.Members = append(st.Members, &mongo.Member{Name: h, Health: mongo.MemberHealthUp, ConfigVersion: versions[i]})
+		}
+		return st
+	}
+
+	tests := []struct {
+		name   string
+		status *mongo.Status
+		want   bool
+	}{
+		{
+			name:   "all members",
+			status: status(5, 5, 5, 5),
+			want:   true,
+		},
+		{
+			name:   "majority",
+			status: status(5, 5, 4, 4),
+			want:   true,
+		},
+		{
+			name:   "minority",
+			status: status(5, 4, 4, 4),
+		},
+		{
+			name:   "minority with non-voting member",
+			status: status(5, 4, 4, 5),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := configCommitted(cnf, tt.status); got != tt.want {
+				t.Errorf("configCommitted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
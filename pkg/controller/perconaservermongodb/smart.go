@@ -8,6 +8,7 @@ import (
 	"time"
 
 	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/metrics"
 	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
 	"github.com/pkg/errors"
 	mgo "go.mongodb.org/mongo-driver/mongo"
@@ -20,7 +21,7 @@ import (
 )
 
 func (r *ReconcilePerconaServerMongoDB) smartUpdate(cr *api.PerconaServerMongoDB, sfs *appsv1.StatefulSet, replset *api.ReplsetSpec, secret *corev1.Secret) error {
-	if cr.Spec.UpdateStrategy != api.SmartUpdateStatefulSetStrategyType {
+	if cr.Spec.UpdateStrategy != api.SmartUpdateStatefulSetStrategyType || cr.Spec.Pause {
 		return nil
 	}
 
@@ -67,17 +68,12 @@ func (r *ReconcilePerconaServerMongoDB) smartUpdate(cr *api.PerconaServerMongoDB
 
 	username := string(secret.Data[envMongoDBClusterAdminUser])
 	password := string(secret.Data[envMongoDBClusterAdminPassword])
-	client, err := r.mongoClient(cr, replset, list, username, password)
+	client, release, err := r.mongoClient(cr, replset, list, username, password)
 	if err != nil {
 		return fmt.Errorf("failed to get mongo client: %v", err)
 	}
 
-	defer func() {
-		err := client.Disconnect(context.TODO())
-		if err != nil {
-			log.Error(err, "failed to close connection")
-		}
-	}()
+	defer release()
 
 	primary, err := r.getPrimaryPod(client)
 	if err != nil {
@@ -92,9 +88,19 @@ func (r *ReconcilePerconaServerMongoDB) smartUpdate(cr *api.PerconaServerMongoDB
 		return list.Items[i].Name > list.Items[j].Name
 	})
 
+	metrics.SmartUpdateStarted(cr, replset.Name, len(list.Items))
+	defer metrics.SmartUpdateFinished(cr, replset.Name)
+
+	// the replset's pods of other StatefulSets (the arbiter and
+	// member pools) are updated by their own smart update
+	sfsPods := labels.SelectorFromSet(sfs.Spec.Selector.MatchLabels)
+
 	var primaryPod corev1.Pod
 	for _, pod := range list.Items {
 		pod := pod
+		if !sfsPods.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
 		if strings.HasPrefix(primary, fmt.Sprintf("%s.%s.%s", pod.Name, sfs.Name, sfs.Namespace)) {
 			primaryPod = pod
 		} else {
@@ -102,19 +108,37 @@ func (r *ReconcilePerconaServerMongoDB) smartUpdate(cr *api.PerconaServerMongoDB
 			if err := r.applyNWait(cr, sfs.Status.UpdateRevision, &pod, waitLimit); err != nil {
 				return fmt.Errorf("failed to apply changes: %v", err)
 			}
+			metrics.SmartUpdatePodUpdated(cr, replset.Name)
 		}
 	}
 
+	// the primary isn't among the StatefulSet's pods
+	if primaryPod.Name == "" {
+		log.Info("smart update finished")
+		return nil
+	}
+
 	log.Info("doing step down...")
 	err = mongo.StepDown(context.TODO(), client)
 	if err != nil {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, eventStepDownFailed, "Step down of primary %s failed: %v", primaryPod.Name, err)
 		return errors.Wrap(err, "failed to do step down")
 	}
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, eventStepDown, "Primary %s stepped down to apply changes", primaryPod.Name)
+
+	// take the former primary out of the primary service before its restart
+	status, err := mongo.RSStatus(context.TODO(), client)
+	if err != nil {
+		log.Error(err, "failed to get replset status after step down")
+	} else if err := r.updatePodRoles(cr, replset, list.Items, &status); err != nil {
+		log.Error(err, "failed to update pods roles after step down")
+	}
 
 	log.Info(fmt.Sprintf("apply changes to primary pod %s", primaryPod.Name))
 	if err := r.applyNWait(cr, sfs.Status.UpdateRevision, &primaryPod, waitLimit); err != nil {
 		return fmt.Errorf("failed to apply changes: %v", err)
 	}
+	metrics.SmartUpdatePodUpdated(cr, replset.Name)
 
 	log.Info("smart update finished")
 
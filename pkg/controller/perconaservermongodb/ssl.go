@@ -2,7 +2,11 @@ package perconaservermongodb
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	cm "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
@@ -37,9 +41,13 @@ func (r *ReconcilePerconaServerMongoDB) reconsileSSL(cr *api.PerconaServerMongoD
 		log.Error(err, "issue cert with cert-manager")
 		err = r.createSSLManualy(cr)
 		if err != nil {
+			r.recorder.Eventf(cr, corev1.EventTypeWarning, eventTLSIssueFailed, "TLS certificates issue failed: %v", err)
 			return fmt.Errorf("create ssl manualy: %v", err)
 		}
+		r.recorder.Event(cr, corev1.EventTypeNormal, eventTLSIssued, "TLS certificates generated by the operator")
+		return nil
 	}
+	r.recorder.Event(cr, corev1.EventTypeNormal, eventTLSIssued, "TLS certificates issued by cert-manager")
 	return nil
 }
 
@@ -51,6 +59,7 @@ func (r *ReconcilePerconaServerMongoDB) createSSLByCertManager(cr *api.PerconaSe
 	for _, replset := range cr.Spec.Replsets {
 		certificateDNSNames = append(certificateDNSNames, getCertificateSans(cr, replset)...)
 	}
+	certificateDNSNames = append(certificateDNSNames, getShardingSans(cr)...)
 	owner, err := OwnerRef(cr, r.scheme)
 	if err != nil {
 		return err
@@ -158,6 +167,7 @@ func (r *ReconcilePerconaServerMongoDB) createSSLManualy(cr *api.PerconaServerMo
 	for _, replset := range cr.Spec.Replsets {
 		certificateDNSNames = append(certificateDNSNames, getCertificateSans(cr, replset)...)
 	}
+	certificateDNSNames = append(certificateDNSNames, getShardingSans(cr)...)
 	caCert, tlsCert, key, err := tls.Issue(certificateDNSNames)
 	if err != nil {
 		return fmt.Errorf("create proxy certificate: %v", err)
@@ -209,6 +219,98 @@ func (r *ReconcilePerconaServerMongoDB) createSSLManualy(cr *api.PerconaServerMo
 	return nil
 }
 
+// ensureExternalSANs makes the cluster's certificate valid for the external
+// addresses of the members, so the clients outside of the cluster can use
+// the external horizon. It returns whether the certificate is valid for all
+// of them. The certificate issued by cert-manager is reissued with the missing
+// hosts. The ones issued by the operator or provided by the user aren't changed,
+// as their CA key isn't kept, and a warning is emitted once for the missing hosts.
+func (r *ReconcilePerconaServerMongoDB) ensureExternalSANs(cr *api.PerconaServerMongoDB, addrs []string) (bool, error) {
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Spec.Secrets.SSL, Namespace: cr.Namespace}, secret)
+	if err != nil {
+		return false, errors.Wrap(err, "get ssl secret")
+	}
+
+	block, _ := pem.Decode(secret.Data["tls.crt"])
+	if block == nil {
+		return false, errors.Errorf("no certificate in secret %s", secret.Name)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false, errors.Wrap(err, "parse certificate")
+	}
+
+	cs := r.clusterSync(types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace})
+	var missing []string
+	for _, addr := range addrs {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		if cert.VerifyHostname(host) != nil {
+			missing = append(missing, host)
+		} else {
+			delete(cs.missingSANs, host)
+		}
+	}
+	if len(missing) == 0 {
+		return true, nil
+	}
+
+	// certificates aren't cached, so the operator works without cert-manager
+	certificate := &cm.Certificate{}
+	err = r.apiReader.Get(context.TODO(), types.NamespacedName{Name: cr.Name + "-ssl", Namespace: cr.Namespace}, certificate)
+	if err == nil {
+		var added []string
+		for _, h := range missing {
+			if net.ParseIP(h) != nil {
+				if !containsString(certificate.Spec.IPAddresses, h) {
+					certificate.Spec.IPAddresses = append(certificate.Spec.IPAddresses, h)
+					added = append(added, h)
+				}
+			} else if !containsString(certificate.Spec.DNSNames, h) {
+				certificate.Spec.DNSNames = append(certificate.Spec.DNSNames, h)
+				added = append(added, h)
+			}
+		}
+		// the hosts are requested already, cert-manager hasn't reissued it yet
+		if len(added) == 0 {
+			return false, nil
+		}
+
+		err = r.client.Update(context.TODO(), certificate)
+		if err != nil {
+			return false, errors.Wrap(err, "update certificate")
+		}
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, eventTLSIssued, "TLS certificate requested from cert-manager for external hosts %s", strings.Join(added, ", "))
+		return false, nil
+	}
+
+	var unwarned []string
+	for _, h := range missing {
+		if !cs.missingSANs[h] {
+			cs.missingSANs[h] = true
+			unwarned = append(unwarned, h)
+		}
+	}
+	if len(unwarned) > 0 {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, eventTLSIssueFailed, "TLS certificate in secret %s isn't valid for external hosts %s, the external horizon is set once it's reissued with them", secret.Name, strings.Join(unwarned, ", "))
+	}
+
+	return false, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
 func getCertificateSans(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec) []string {
 	return []string{
 		cr.Name + "-" + replset.Name,
@@ -219,3 +321,16 @@ func getCertificateSans(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec)
 		"*." + cr.Name + "-" + replset.Name + "." + cr.Namespace + "." + cr.Spec.ClusterServiceDNSSuffix,
 	}
 }
+
+// getShardingSans returns SANs of the mongos service. They're always added
+// so the certificate stays valid if sharding is enabled later
+func getShardingSans(cr *api.PerconaServerMongoDB) []string {
+	return []string{
+		cr.Name + "-mongos",
+		cr.Name + "-mongos." + cr.Namespace,
+		cr.Name + "-mongos." + cr.Namespace + "." + cr.Spec.ClusterServiceDNSSuffix,
+		"*." + cr.Name + "-mongos",
+		"*." + cr.Name + "-mongos." + cr.Namespace,
+		"*." + cr.Name + "-mongos." + cr.Namespace + "." + cr.Spec.ClusterServiceDNSSuffix,
+	}
+}
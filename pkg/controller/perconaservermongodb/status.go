@@ -3,12 +3,15 @@ package perconaservermongodb
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
@@ -65,6 +68,10 @@ func (r *ReconcilePerconaServerMongoDB) updateStatus(cr *api.PerconaServerMongoD
 		}
 
 		status.Initialized = currentRSstatus.Initialized
+		status.AddedAsShard = currentRSstatus.AddedAsShard
+		status.Members = currentRSstatus.Members
+		status.Primary = currentRSstatus.Primary
+		status.StorageAutoscaling = currentRSstatus.StorageAutoscaling
 
 		if status.Status == api.AppStateReady {
 			replsetsReady++
@@ -99,8 +106,54 @@ func (r *ReconcilePerconaServerMongoDB) updateStatus(cr *api.PerconaServerMongoD
 		}
 	}
 
+	mongosReady := true
+	if cr.Spec.Sharding.Enabled {
+		status, err := r.mongosStatus(cr)
+		if err != nil {
+			return errors.Wrap(err, "get mongos status")
+		}
+		cr.Status.Mongos = status
+		mongosReady = status.Status == api.AppStateReady
+
+		if !inProgress {
+			inProgress, err = r.mongosUpgradeInProgress(cr)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return errors.Wrap(err, "check mongos upgrade progress")
+			}
+		}
+	} else {
+		cr.Status.Mongos = nil
+	}
+
+	paused := false
+	if cr.Spec.Pause {
+		var err error
+		paused, err = r.isPaused(cr)
+		if err != nil {
+			return errors.Wrap(err, "check pause")
+		}
+	}
+
+	if paused && cr.Status.State != api.AppStatePaused {
+		r.recorder.Event(cr, corev1.EventTypeNormal, eventClusterPaused, "All pods of the cluster are stopped")
+	}
+
 	cr.Status.State = api.AppStateInit
-	if replsetsReady == len(cr.Spec.Replsets) && clusterState == clusterReady {
+	if paused {
+		clusterCondition = api.ClusterCondition{
+			Status:             api.ConditionTrue,
+			Type:               api.ClusterPaused,
+			LastTransitionTime: metav1.NewTime(time.Now()),
+		}
+		cr.Status.State = api.AppStatePaused
+	} else if cr.Spec.Pause {
+		// the pods are still being stopped
+		clusterCondition = api.ClusterCondition{
+			Status:             api.ConditionTrue,
+			Type:               api.ClusterInit,
+			LastTransitionTime: metav1.NewTime(time.Now()),
+		}
+	} else if replsetsReady == len(cr.Spec.Replsets) && mongosReady && clusterState == clusterReady {
 
 		clusterCondition = api.ClusterCondition{
 			Status:             api.ConditionTrue,
@@ -137,11 +190,13 @@ func (r *ReconcilePerconaServerMongoDB) updateStatus(cr *api.PerconaServerMongoD
 		}
 	}
 
+	setLaggingCondition(cr)
+
 	if len(cr.Status.Conditions) > maxStatusesQuantity {
 		cr.Status.Conditions = cr.Status.Conditions[len(cr.Status.Conditions)-maxStatusesQuantity:]
 	}
 
-	if inProgress {
+	if inProgress && !paused {
 		cr.Status.State = api.AppStateInit
 	}
 
@@ -150,10 +205,67 @@ func (r *ReconcilePerconaServerMongoDB) updateStatus(cr *api.PerconaServerMongoD
 	return r.writeStatus(cr)
 }
 
+// setLaggingCondition sets the ReplicationLagging condition if any replset
+// member is behind the primary more than the configured threshold.
+// The condition is kept in place instead of being appended to the history,
+// so the lag fluctuations don't flood the conditions list.
+func setLaggingCondition(cr *api.PerconaServerMongoDB) {
+	var lagging []string
+	for _, rs := range cr.Spec.Replsets {
+		status, ok := cr.Status.Replsets[rs.Name]
+		if !ok {
+			continue
+		}
+		for _, m := range status.Members {
+			if m.LagSeconds > cr.Spec.ReplicationLagThreshold {
+				lagging = append(lagging, fmt.Sprintf("%s (%ds)", m.Name, m.LagSeconds))
+			}
+		}
+	}
+
+	cond := api.ClusterCondition{
+		Status: api.ConditionFalse,
+		Type:   api.ClusterLagging,
+	}
+	if len(lagging) > 0 {
+		cond.Status = api.ConditionTrue
+		cond.Reason = "LagThresholdExceeded"
+		cond.Message = "replication lag is above " + strconv.FormatInt(cr.Spec.ReplicationLagThreshold, 10) + "s: " + strings.Join(lagging, ", ")
+	}
+
+	// there is no need in the condition until the lag is noticed
+	if !updateConditionInPlace(cr, cond) && cond.Status == api.ConditionTrue {
+		cond.LastTransitionTime = metav1.NewTime(time.Now())
+		cr.Status.Conditions = append(cr.Status.Conditions, cond)
+	}
+}
+
+// updateConditionInPlace updates the cluster's condition of the same type
+// and returns whether there is one
+func updateConditionInPlace(cr *api.PerconaServerMongoDB, cond api.ClusterCondition) bool {
+	for i := range cr.Status.Conditions {
+		c := &cr.Status.Conditions[i]
+		if c.Type != cond.Type {
+			continue
+		}
+		if c.Status != cond.Status {
+			c.LastTransitionTime = metav1.NewTime(time.Now())
+		}
+		c.Status, c.Reason, c.Message = cond.Status, cond.Reason, cond.Message
+		return true
+	}
+
+	return false
+}
+
 func (r *ReconcilePerconaServerMongoDB) upgradeInProgress(cr *api.PerconaServerMongoDB, rsName string) (bool, error) {
 	sfsObj := &appsv1.StatefulSet{}
 	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name + "-" + rsName, Namespace: cr.Namespace}, sfsObj)
 	if err != nil {
+		// the StatefulSet is being recreated, e.g. after its volumes are resized
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
 		return false, err
 	}
 
@@ -197,6 +309,15 @@ func (r *ReconcilePerconaServerMongoDB) rsStatus(rsSpec *api.ReplsetSpec, cluste
 		Size:   rsSpec.Size,
 		Status: api.AppStateInit,
 	}
+	// the ready pods are counted for all StatefulSets of the replset
+	if rsSpec.Arbiter.Enabled {
+		status.Size += rsSpec.Arbiter.Size
+	}
+	for _, name := range api.MemberPools {
+		if pool := rsSpec.MemberPool(name); pool != nil {
+			status.Size += pool.Size
+		}
+	}
 
 	for _, pod := range list.Items {
 		for _, cond := range pod.Status.Conditions {
@@ -85,6 +85,7 @@ func (r *ReconcilePerconaServerMongoDB) reconcileUsers(cr *api.PerconaServerMong
 
 	restartSfs, err := r.updateSysUsers(cr, &sysUsersSecretObj, &internalSysSecretObj)
 	if err != nil {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, eventUsersUpdateFailed, "System users update failed: %v", err)
 		return nil, errors.Wrap(err, "manage sys users")
 	}
 
@@ -93,6 +94,7 @@ func (r *ReconcilePerconaServerMongoDB) reconcileUsers(cr *api.PerconaServerMong
 	if err != nil {
 		return nil, errors.Wrap(err, "update internal sys users secret")
 	}
+	r.recorder.Event(cr, corev1.EventTypeNormal, eventUsersUpdated, "System users and passwords updated")
 
 	if restartSfs {
 		return map[string]string{
@@ -206,51 +208,52 @@ func (r *ReconcilePerconaServerMongoDB) updateSysUsers(cr *api.PerconaServerMong
 	return restartSfs, errors.Wrap(err, "mongo: update system users")
 }
 
+// updateUsers updates the system users in every replset
+// since each of them keeps its own users
 func (r *ReconcilePerconaServerMongoDB) updateUsers(cr *api.PerconaServerMongoDB, users []systemUser, usersSecret *corev1.Secret) error {
-	for i, replset := range cr.Spec.Replsets {
-		if i > 0 {
-			log.Info("update users: multiple replica sets is not yet supported")
-			return nil
+	for _, replset := range cr.Spec.Replsets {
+		err := r.updateReplsetUsers(cr, replset, users, usersSecret)
+		if err != nil {
+			return err
 		}
+	}
 
-		matchLabels := map[string]string{
-			"app.kubernetes.io/name":       "percona-server-mongodb",
-			"app.kubernetes.io/instance":   cr.Name,
-			"app.kubernetes.io/replset":    replset.Name,
-			"app.kubernetes.io/managed-by": "percona-server-mongodb-operator",
-			"app.kubernetes.io/part-of":    "percona-server-mongodb",
-		}
+	return nil
+}
 
-		pods := corev1.PodList{}
-		err := r.client.List(context.TODO(),
-			&pods,
-			&client.ListOptions{
-				Namespace:     cr.Namespace,
-				LabelSelector: labels.SelectorFromSet(matchLabels),
-			},
-		)
+func (r *ReconcilePerconaServerMongoDB) updateReplsetUsers(cr *api.PerconaServerMongoDB, replset *api.ReplsetSpec, users []systemUser, usersSecret *corev1.Secret) error {
+	matchLabels := map[string]string{
+		"app.kubernetes.io/name":       "percona-server-mongodb",
+		"app.kubernetes.io/instance":   cr.Name,
+		"app.kubernetes.io/replset":    replset.Name,
+		"app.kubernetes.io/managed-by": "percona-server-mongodb-operator",
+		"app.kubernetes.io/part-of":    "percona-server-mongodb",
+	}
 
-		username := string(usersSecret.Data[envMongoDBUserAdminUser])
-		password := string(usersSecret.Data[envMongoDBUserAdminPassword])
-		if err != nil {
-			return errors.Wrapf(err, "get pods list for replset %s", replset.Name)
-		}
-		client, err := r.mongoClient(cr, replset, pods, username, password)
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(matchLabels),
+		},
+	)
+	if err != nil {
+		return errors.Wrapf(err, "get pods list for replset %s", replset.Name)
+	}
+
+	username := string(usersSecret.Data[envMongoDBUserAdminUser])
+	password := string(usersSecret.Data[envMongoDBUserAdminPassword])
+	client, release, err := r.mongoClient(cr, replset, pods, username, password)
+	if err != nil {
+		return errors.Wrapf(err, "dial replset %s", replset.Name)
+	}
+	defer release()
+
+	for _, user := range users {
+		err := user.updateMongo(client)
 		if err != nil {
-			return errors.Wrap(err, "dial:")
-		}
-		defer func() {
-			err := client.Disconnect(context.TODO())
-			if err != nil {
-				log.Error(err, "failed to close connection")
-			}
-		}()
-
-		for _, user := range users {
-			err := user.updateMongo(client)
-			if err != nil {
-				return errors.Wrapf(err, "updateUsers in mongo for replset %s", replset.Name)
-			}
+			return errors.Wrapf(err, "updateUsers in mongo for replset %s", replset.Name)
 		}
 	}
 
@@ -8,28 +8,37 @@ import (
 
 	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
 	v1 "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/metrics"
 	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
 	"github.com/pkg/errors"
 	"github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 )
 
-const jobName = "ensure-version"
+// ensureVersionJobName returns the key of the cluster's version check job in the cron registry
+func ensureVersionJobName(cr *api.PerconaServerMongoDB) string {
+	return "ensure-version/" + cr.Namespace + "/" + cr.Name
+}
 
-func (r *ReconcilePerconaServerMongoDB) deleteEnsureVersion(id int) {
+func (r *ReconcilePerconaServerMongoDB) deleteEnsureVersion(cr *api.PerconaServerMongoDB, id int) {
 	r.crons.crons.Remove(cron.EntryID(id))
-	delete(r.crons.jobs, jobName)
+	delete(r.crons.jobs, ensureVersionJobName(cr))
 }
 
 func (r *ReconcilePerconaServerMongoDB) sheduleEnsureVersion(cr *api.PerconaServerMongoDB, vs VersionService) error {
+	r.crons.jobsMutex.Lock()
+	defer r.crons.jobsMutex.Unlock()
+
+	jobName := ensureVersionJobName(cr)
 	schedule, ok := r.crons.jobs[jobName]
 	if cr.Spec.UpdateStrategy != v1.SmartUpdateStatefulSetStrategyType ||
 		cr.Spec.UpgradeOptions.Schedule == "" ||
 		cr.Spec.UpgradeOptions.Apply.Lower() == api.UpgradeStrategyNever ||
 		cr.Spec.UpgradeOptions.Apply.Lower() == api.UpgradeStrategyDiasbled {
 		if ok {
-			r.deleteEnsureVersion(schedule.ID)
+			r.deleteEnsureVersion(cr, schedule.ID)
 		}
 
 		return nil
@@ -41,22 +50,34 @@ func (r *ReconcilePerconaServerMongoDB) sheduleEnsureVersion(cr *api.PerconaServ
 
 	if ok {
 		log.Info(fmt.Sprintf("remove job %s because of new %s", schedule.CronShedule, cr.Spec.UpgradeOptions.Schedule))
-		r.deleteEnsureVersion(schedule.ID)
+		r.deleteEnsureVersion(cr, schedule.ID)
 	}
 
-	log.Info(fmt.Sprintf("add new job: %s", cr.Spec.UpgradeOptions.Schedule))
+	log.Info(fmt.Sprintf("add new job: %s", cr.Spec.UpgradeOptions.Schedule), "cluster", cr.Name, "namespace", cr.Namespace)
+	nn := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
 	id, err := r.crons.crons.AddFunc(cr.Spec.UpgradeOptions.Schedule, func() {
-		r.statusMutex.Lock()
-		defer r.statusMutex.Unlock()
+		cs := r.clusterSync(nn)
+		cs.statusMutex.Lock()
+		defer cs.statusMutex.Unlock()
 
-		if !atomic.CompareAndSwapInt32(&r.updateSync, updateDone, updateWait) {
+		if !atomic.CompareAndSwapInt32(&cs.updateSync, updateDone, updateWait) {
 			return
 		}
 
 		localCr := &api.PerconaServerMongoDB{}
-		err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, localCr)
+		err := r.client.Get(context.TODO(), nn, localCr)
 		if err != nil {
-			log.Error(err, "failed to get CR")
+			if k8serrors.IsNotFound(err) {
+				// the cluster is deleted
+				localCr.Name, localCr.Namespace = nn.Name, nn.Namespace
+				r.crons.jobsMutex.Lock()
+				if job, ok := r.crons.jobs[ensureVersionJobName(localCr)]; ok {
+					r.deleteEnsureVersion(localCr, job.ID)
+				}
+				r.crons.jobsMutex.Unlock()
+				return
+			}
+			log.Error(err, "failed to get CR", "cluster", nn.Name, "namespace", nn.Namespace)
 			return
 		}
 
@@ -113,6 +134,7 @@ func (r *ReconcilePerconaServerMongoDB) ensureVersion(cr *api.PerconaServerMongo
 	}
 
 	newVersion, err := vs.GetExactVersion(cr.Spec.UpgradeOptions.VersionServiceEndpoint, vm)
+	metrics.VersionChecked(cr, err)
 	if err != nil {
 		return fmt.Errorf("failed to check version: %v", err)
 	}
@@ -185,17 +207,12 @@ func (r *ReconcilePerconaServerMongoDB) fetchVersionFromMongo(cr *api.PerconaSer
 
 	username := string(usersSecret.Data[envMongoDBClusterAdminUser])
 	password := string(usersSecret.Data[envMongoDBClusterAdminPassword])
-	session, err := r.mongoClient(cr, replset, pods, username, password)
+	session, release, err := r.mongoClient(cr, replset, pods, username, password)
 	if err != nil {
 		return errors.Wrap(err, "dial")
 	}
 
-	defer func() {
-		err := session.Disconnect(context.TODO())
-		if err != nil {
-			log.Error(err, "failed to close connection")
-		}
-	}()
+	defer release()
 
 	info, err := mongo.RSBuildInfo(context.Background(), session)
 	if err != nil {
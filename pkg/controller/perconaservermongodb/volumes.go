@@ -0,0 +1,217 @@
+package perconaservermongodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb"
+)
+
+// claimStorage returns the storage requested by the data volume claim template
+func claimStorage(templates []corev1.PersistentVolumeClaim) (resource.Quantity, bool) {
+	for _, t := range templates {
+		if t.Name == psmdb.MongodDataVolClaimName {
+			q, ok := t.Spec.Resources.Requests[corev1.ResourceStorage]
+			return q, ok
+		}
+	}
+
+	return resource.Quantity{}, false
+}
+
+// reconcileVolumes expands the data volumes of the StatefulSet once the
+// requested storage grows. As the claim templates of the StatefulSet are
+// immutable, the current ones are kept in the spec and it returns whether
+// the StatefulSet should be recreated with the new templates.
+func (r *ReconcilePerconaServerMongoDB) reconcileVolumes(cr *api.PerconaServerMongoDB, sfs *appsv1.StatefulSet, spec *appsv1.StatefulSetSpec) (bool, error) {
+	oldSize, okOld := claimStorage(sfs.Spec.VolumeClaimTemplates)
+	newSize, okNew := claimStorage(spec.VolumeClaimTemplates)
+	spec.VolumeClaimTemplates = sfs.Spec.VolumeClaimTemplates
+
+	pvcs, err := r.dataVolumeClaims(sfs)
+	if err != nil {
+		return false, err
+	}
+
+	if !okOld || !okNew || newSize.Cmp(oldSize) <= 0 {
+		if okOld && okNew && newSize.Cmp(oldSize) < 0 {
+			log.Info("data volumes can't be shrunk", "StatefulSet", sfs.Name, "size", oldSize.String(), "requested", newSize.String())
+		}
+		return false, r.finishVolumesResize(cr, sfs, pvcs)
+	}
+
+	for _, pvc := range pvcs {
+		ok, err := r.isVolumeExpandable(pvc)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			setVolumesCondition(cr, api.ConditionFalse, "ExpansionNotSupported",
+				fmt.Sprintf("%s: storage class of %s doesn't allow volume expansion", sfs.Name, pvc.Name))
+			r.recorder.Eventf(cr, corev1.EventTypeWarning, eventVolumesResizeFailed, "Volumes of %s can't be resized to %s: storage class of %s doesn't allow volume expansion", sfs.Name, newSize.String(), pvc.Name)
+			return false, nil
+		}
+	}
+
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		if q := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; q.Cmp(newSize) >= 0 {
+			continue
+		}
+
+		orig := pvc.DeepCopy()
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = make(corev1.ResourceList)
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = newSize
+		err := r.client.Patch(context.TODO(), pvc, client.MergeFrom(orig))
+		if err != nil {
+			setVolumesCondition(cr, api.ConditionFalse, "ExpansionFailed", fmt.Sprintf("%s: resize %s: %v", sfs.Name, pvc.Name, err))
+			r.recorder.Eventf(cr, corev1.EventTypeWarning, eventVolumesResizeFailed, "Volume %s resize to %s failed: %v", pvc.Name, newSize.String(), err)
+			return false, errors.Wrapf(err, "resize PVC %s", pvc.Name)
+		}
+	}
+
+	setVolumesCondition(cr, api.ConditionTrue, "Resizing", fmt.Sprintf("%s: resizing volumes to %s", sfs.Name, newSize.String()))
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, eventVolumesResized, "Volumes of %s are resizing to %s", sfs.Name, newSize.String())
+
+	return true, nil
+}
+
+// dataVolumeClaims returns the data volume claims of the StatefulSet's pods
+// sorted by their names
+func (r *ReconcilePerconaServerMongoDB) dataVolumeClaims(sfs *appsv1.StatefulSet) ([]corev1.PersistentVolumeClaim, error) {
+	list := corev1.PersistentVolumeClaimList{}
+	err := r.client.List(context.TODO(),
+		&list,
+		&client.ListOptions{
+			Namespace:     sfs.Namespace,
+			LabelSelector: labels.SelectorFromSet(sfs.Spec.Selector.MatchLabels),
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "get PVC list")
+	}
+
+	prefix := psmdb.MongodDataVolClaimName + "-" + sfs.Name + "-"
+	pvcs := list.Items[:0]
+	for _, pvc := range list.Items {
+		if strings.HasPrefix(pvc.Name, prefix) {
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	sort.Slice(pvcs, func(i, j int) bool { return pvcs[i].Name < pvcs[j].Name })
+
+	return pvcs, nil
+}
+
+// isVolumeExpandable returns whether the storage class of the claim allows
+// volume expansion. The storage classes aren't cached, and if the operator
+// can't read them, the expansion is left to be checked by the API server.
+func (r *ReconcilePerconaServerMongoDB) isVolumeExpandable(pvc corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return true, nil
+	}
+
+	sc := &storagev1.StorageClass{}
+	err := r.apiReader.Get(context.TODO(), types.NamespacedName{Name: *pvc.Spec.StorageClassName}, sc)
+	if err != nil {
+		if k8serrors.IsNotFound(err) || k8serrors.IsForbidden(err) {
+			return true, nil
+		}
+		return false, errors.Wrapf(err, "get storage class %s", *pvc.Spec.StorageClassName)
+	}
+
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
+
+// finishVolumesResize restarts the pods whose volumes wait for the filesystem
+// resize, one at a time once the rest of the pods are ready, and resets
+// the condition when the volumes of the StatefulSet are resized
+func (r *ReconcilePerconaServerMongoDB) finishVolumesResize(cr *api.PerconaServerMongoDB, sfs *appsv1.StatefulSet, pvcs []corev1.PersistentVolumeClaim) error {
+	resizing := false
+	for _, pvc := range pvcs {
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(requested) >= 0 {
+			continue
+		}
+		resizing = true
+
+		if !isFSResizePending(pvc) || sfs.Spec.Replicas == nil || sfs.Status.ReadyReplicas < *sfs.Spec.Replicas {
+			continue
+		}
+
+		pod := &corev1.Pod{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{
+			Name:      strings.TrimPrefix(pvc.Name, psmdb.MongodDataVolClaimName+"-"),
+			Namespace: pvc.Namespace,
+		}, pod)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "get pod of PVC %s", pvc.Name)
+		}
+
+		err = r.client.Delete(context.TODO(), pod)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "restart pod %s", pod.Name)
+		}
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, eventVolumesResized, "Pod %s restarted to resize the filesystem of %s", pod.Name, pvc.Name)
+		// the next one is restarted after this pod is ready
+		return nil
+	}
+
+	if resizing {
+		return nil
+	}
+
+	for _, c := range cr.Status.Conditions {
+		if c.Type == api.ClusterVolumesResizing && c.Status == api.ConditionTrue && strings.HasPrefix(c.Message, sfs.Name+":") {
+			setVolumesCondition(cr, api.ConditionFalse, "Resized", sfs.Name+": volumes are resized")
+		}
+	}
+
+	return nil
+}
+
+func isFSResizePending(pvc corev1.PersistentVolumeClaim) bool {
+	for _, c := range pvc.Status.Conditions {
+		if c.Type == corev1.PersistentVolumeClaimFileSystemResizePending && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setVolumesCondition sets the VolumesResizing condition. Like the replication
+// lag condition, it's kept in place instead of being appended to the history.
+func setVolumesCondition(cr *api.PerconaServerMongoDB, status api.ConditionStatus, reason, message string) {
+	cond := api.ClusterCondition{
+		Type:    api.ClusterVolumesResizing,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+	if !updateConditionInPlace(cr, cond) {
+		cond.LastTransitionTime = metav1.NewTime(time.Now())
+		cr.Status.Conditions = append(cr.Status.Conditions, cond)
+	}
+}
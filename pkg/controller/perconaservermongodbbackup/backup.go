@@ -2,10 +2,13 @@ package perconaservermongodbbackup
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/percona/percona-backup-mongodb/pbm"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -14,8 +17,9 @@ import (
 )
 
 type Backup struct {
-	pbm  *backup.PBM
-	spec api.BackupSpec
+	pbms     []*backup.PBM
+	spec     api.BackupSpec
+	replsets []*api.ReplsetSpec
 }
 
 func (r *ReconcilePerconaServerMongoDBBackup) newBackup(cr *api.PerconaServerMongoDBBackup) (*Backup, error) {
@@ -25,18 +29,25 @@ func (r *ReconcilePerconaServerMongoDBBackup) newBackup(cr *api.PerconaServerMon
 		return nil, errors.Wrapf(err, "get cluster %s/%s", cr.Namespace, cr.Spec.PSMDBCluster)
 	}
 
-	cn, err := backup.NewPBM(r.client, cluster)
+	err = cluster.Spec.Backup.SetStoragesDefaults()
+	if err != nil {
+		return nil, errors.Wrap(err, "check backup storages")
+	}
+
+	pbms, err := backup.NewPBMs(r.client, cluster)
 	if err != nil {
 		return nil, errors.Wrap(err, "create pbm object")
 	}
 
 	return &Backup{
-		pbm:  cn,
-		spec: cluster.Spec.Backup,
+		pbms:     pbms,
+		spec:     cluster.Spec.Backup,
+		replsets: cluster.Spec.Replsets,
 	}, nil
 }
 
-// Start requests backup on PBM
+// Start requests backup on PBM. Separately backed up replsets
+// get the backup of the same name.
 func (b *Backup) Start(cr *api.PerconaServerMongoDBBackup) (api.PerconaServerMongoDBBackupStatus, error) {
 	var status api.PerconaServerMongoDBBackupStatus
 
@@ -45,22 +56,24 @@ func (b *Backup) Start(cr *api.PerconaServerMongoDBBackup) (api.PerconaServerMon
 		return status, errors.Errorf("unable to get storage '%s'", cr.Spec.StorageName)
 	}
 
-	err := b.pbm.SetConfig(stg)
-	if err != nil {
-		return api.PerconaServerMongoDBBackupStatus{}, errors.Wrapf(err, "set backup config with sorage %s", cr.Spec.StorageName)
-	}
-
 	name := time.Now().UTC().Format(time.RFC3339)
 
-	err = b.pbm.C.SendCmd(pbm.Cmd{
-		Cmd: pbm.CmdBackup,
-		Backup: pbm.BackupCmd{
-			Name:        name,
-			Compression: cr.Spec.Comperssion,
-		},
-	})
-	if err != nil {
-		return status, err
+	for _, pbmc := range b.pbms {
+		err := pbmc.SetConfig(stg)
+		if err != nil {
+			return api.PerconaServerMongoDBBackupStatus{}, replsetErr(pbmc, errors.Wrapf(err, "set backup config with sorage %s", cr.Spec.StorageName))
+		}
+
+		err = pbmc.C.SendCmd(pbm.Cmd{
+			Cmd: pbm.CmdBackup,
+			Backup: pbm.BackupCmd{
+				Name:        name,
+				Compression: cr.Spec.Comperssion,
+			},
+		})
+		if err != nil {
+			return status, replsetErr(pbmc, err)
+		}
 	}
 
 	status = api.PerconaServerMongoDBBackupStatus{
@@ -69,58 +82,248 @@ func (b *Backup) Start(cr *api.PerconaServerMongoDBBackup) (api.PerconaServerMon
 		LastTransition: &metav1.Time{
 			Time: time.Unix(time.Now().Unix(), 0),
 		},
-		S3:    &stg.S3,
 		State: api.BackupStateRequested,
 	}
 
-	if stg.S3.Prefix != "" {
-		status.Destination = stg.S3.Prefix + "/"
+	for _, rs := range b.replsets {
+		status.Replsets = append(status.Replsets, api.BackupReplsetStatus{
+			Name:  rs.Name,
+			State: api.BackupStateRequested,
+		})
+	}
+
+	switch stg.Type {
+	case api.BackupStorageS3:
+		status.S3 = &stg.S3
+		if stg.S3.Prefix != "" {
+			status.Destination = stg.S3.Prefix + "/"
+		}
+	case api.BackupStorageFilesystem:
+		status.Destination = stg.Filesystem.Path + "/"
+	case api.BackupStorageAzure:
+		status.Azure = stg.Azure
+		if stg.Azure.Prefix != "" {
+			status.Destination = stg.Azure.Prefix + "/"
+		}
+	case api.BackupStorageGCS:
+		status.GCS = stg.GCS
+		if stg.GCS.Prefix != "" {
+			status.Destination = stg.GCS.Prefix + "/"
+		}
 	}
 	status.Destination += status.PBMname
 
 	return status, nil
 }
 
-// Status return backup status
+// Status return backup status. The backup of separately backed up
+// replsets is running until all of them are finished, and it's failed
+// if any of them is failed.
 func (b *Backup) Status(cr *api.PerconaServerMongoDBBackup) (api.PerconaServerMongoDBBackupStatus, error) {
 	status := cr.Status
 
-	meta, err := b.pbm.C.GetBackupMeta(cr.Status.PBMname)
-	if err != nil {
-		return status, errors.Wrap(err, "get pbm backup meta")
+	metas := make([]*pbm.BackupMeta, 0, len(b.pbms))
+	for _, pbmc := range b.pbms {
+		meta, err := pbmc.C.GetBackupMeta(cr.Status.PBMname)
+		if err != nil {
+			return status, replsetErr(pbmc, errors.Wrap(err, "get pbm backup meta"))
+		}
+		if meta == nil || meta.Name == "" {
+			log.Info("No backup found", "PBM name", cr.Status.PBMname, "backup", cr.Name, "replset", pbmc.Replset())
+			return status, nil
+		}
+		metas = append(metas, meta)
 	}
-	if meta == nil || meta.Name == "" {
-		log.Info("No backup found", "PBM name", cr.Status.PBMname, "backup", cr.Name)
-		return status, nil
+
+	var startTS, lastTS int64
+	var errs []string
+	running := false
+	for i, meta := range metas {
+		if meta.StartTS > 0 && (startTS == 0 || meta.StartTS < startTS) {
+			startTS = meta.StartTS
+		}
+		if meta.LastTransitionTS > lastTS {
+			lastTS = meta.LastTransitionTS
+		}
+
+		switch meta.Status {
+		case pbm.StatusError:
+			errs = append(errs, replsetErr(b.pbms[i], errors.New(meta.Error)).Error())
+		case pbm.StatusDone:
+		default:
+			running = true
+		}
+
+		for _, rs := range meta.Replsets {
+			status.Replsets = setReplsetStatus(status.Replsets, api.BackupReplsetStatus{
+				Name:  rs.Name,
+				State: backupState(rs.Status),
+				Error: rs.Error,
+			})
+		}
 	}
 
-	if meta.StartTS > 0 {
+	if startTS > 0 {
 		status.StartAt = &metav1.Time{
-			Time: time.Unix(meta.StartTS, 0),
+			Time: time.Unix(startTS, 0),
 		}
 	}
 
-	switch meta.Status {
-	case pbm.StatusError:
+	switch {
+	case running:
+		status.State = api.BackupStateRunning
+	case len(errs) > 0:
 		status.State = api.BackupStateError
-		status.Error = meta.Error
-	case pbm.StatusDone:
+		status.Error = strings.Join(errs, "; ")
+	default:
 		status.State = api.BackupStateReady
 		status.CompletedAt = &metav1.Time{
-			Time: time.Unix(meta.LastTransitionTS, 0),
+			Time: time.Unix(lastTS, 0),
 		}
-	default:
-		status.State = api.BackupStateRunning
 	}
 
 	status.LastTransition = &metav1.Time{
-		Time: time.Unix(meta.LastTransitionTS, 0),
+		Time: time.Unix(lastTS, 0),
 	}
 
 	return status, nil
 }
 
-// Close closes the PBM connection
-func (b *Backup) Close() error {
-	return b.pbm.Close()
+// replsetErr adds the name of the replset to the error
+// of the PBM if replsets are backed up separately
+func replsetErr(pbmc *backup.PBM, err error) error {
+	if pbmc.Replset() == "" {
+		return err
+	}
+
+	return errors.Wrapf(err, "replset %s", pbmc.Replset())
+}
+
+// setReplsetStatus replaces the status of the replset in the list or adds it
+func setReplsetStatus(list []api.BackupReplsetStatus, rs api.BackupReplsetStatus) []api.BackupReplsetStatus {
+	for i := range list {
+		if list[i].Name == rs.Name {
+			list[i] = rs
+			return list
+		}
+	}
+
+	return append(list, rs)
+}
+
+func backupState(s pbm.Status) api.BackupState {
+	switch s {
+	case pbm.StatusError:
+		return api.BackupStateError
+	case pbm.StatusDone:
+		return api.BackupStateReady
+	default:
+		return api.BackupStateRunning
+	}
+}
+
+// Close closes the PBM connections
+func (b *Backup) Close() {
+	backup.ClosePBMs(b.pbms)
+}
+
+// reconcileFinalizer adds the finalizer to the backup which should be
+// deleted from the storage with the object and removes it otherwise
+func (r *ReconcilePerconaServerMongoDBBackup) reconcileFinalizer(cr *api.PerconaServerMongoDBBackup) error {
+	need := cr.Spec.DeleteFromStorage
+	if need == api.HasFinalizer(cr, api.FinalizerDeleteBackup) {
+		return nil
+	}
+
+	if need {
+		cr.SetFinalizers(append(cr.GetFinalizers(), api.FinalizerDeleteBackup))
+	} else {
+		api.RemoveFinalizer(cr, api.FinalizerDeleteBackup)
+	}
+
+	return errors.Wrap(r.client.Update(context.TODO(), cr), "update finalizers")
+}
+
+// deleteFromStorage deletes the backup data through PBM and removes the
+// finalizer, so the deleted object goes away. The running backup is deleted
+// once it's finished. If the cluster or the storage is gone, or the storage
+// is reachable only by agents, the data can't be deleted, and the object
+// is released as is.
+func (r *ReconcilePerconaServerMongoDBBackup) deleteFromStorage(cr *api.PerconaServerMongoDBBackup) error {
+	if !api.HasFinalizer(cr, api.FinalizerDeleteBackup) {
+		return nil
+	}
+
+	switch cr.Status.State {
+	case api.BackupStateRequested, api.BackupStateRunning:
+		return nil
+	case api.BackupStateReady, api.BackupStateError:
+		if cr.Status.PBMname == "" {
+			break
+		}
+
+		deleted, err := r.deletePBMBackup(cr)
+		if err != nil {
+			r.recorder.Eventf(cr, corev1.EventTypeWarning, eventBackupDeleteFailed, "Backup %s deletion from storage %s failed: %v", cr.Status.PBMname, cr.Spec.StorageName, err)
+			return err
+		}
+		if !deleted {
+			return nil
+		}
+	}
+
+	api.RemoveFinalizer(cr, api.FinalizerDeleteBackup)
+	return errors.Wrap(r.client.Update(context.TODO(), cr), "remove finalizer")
+}
+
+// deletePBMBackup deletes the backup from the storage. It returns false
+// if the deletion should wait for the cluster's running backups and restores.
+func (r *ReconcilePerconaServerMongoDBBackup) deletePBMBackup(cr *api.PerconaServerMongoDBBackup) (bool, error) {
+	cluster := &api.PerconaServerMongoDB{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Spec.PSMDBCluster, Namespace: cr.Namespace}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			r.recorder.Eventf(cr, corev1.EventTypeWarning, eventBackupDeleteSkipped, "Backup %s isn't deleted from storage: cluster %s not found", cr.Status.PBMname, cr.Spec.PSMDBCluster)
+			return true, nil
+		}
+		return false, errors.Wrapf(err, "get cluster %s/%s", cr.Namespace, cr.Spec.PSMDBCluster)
+	}
+
+	err = cluster.Spec.Backup.SetStoragesDefaults()
+	if err != nil {
+		return false, errors.Wrap(err, "check backup storages")
+	}
+	stg, ok := cluster.Spec.Backup.Storages[cr.Spec.StorageName]
+	if !ok {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, eventBackupDeleteSkipped, "Backup %s isn't deleted from storage: storage %s not found", cr.Status.PBMname, cr.Spec.StorageName)
+		return true, nil
+	}
+	if !stg.Type.OperatorAccessible() {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, eventBackupDeleteSkipped, "Backup %s isn't deleted from storage: deleting from %s storage %s isn't supported", cr.Status.PBMname, stg.Type, cr.Spec.StorageName)
+		return true, nil
+	}
+
+	// deletion changes the pbm storage config
+	// so it shouldn't interfere with running jobs
+	active, err := backup.HasActiveJobs(r.client, cr.Spec.PSMDBCluster, cr.Namespace, backup.Job{Name: cr.Name, Type: backup.TypeBackup})
+	if err != nil {
+		return false, errors.Wrap(err, "check for active jobs")
+	}
+	if active {
+		return false, nil
+	}
+
+	pbms, err := backup.NewPBMs(r.client, cluster)
+	if err != nil {
+		return false, errors.Wrap(err, "create pbm object")
+	}
+	defer backup.ClosePBMs(pbms)
+
+	err = backup.DeleteClusterBackup(pbms, cr.Status.PBMname, stg)
+	if err != nil {
+		return false, err
+	}
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, eventBackupDeleted, "Backup %s deleted from storage %s", cr.Status.PBMname, cr.Spec.StorageName)
+
+	return true, nil
 }
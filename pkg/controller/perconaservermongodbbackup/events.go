@@ -0,0 +1,11 @@
+package perconaservermongodbbackup
+
+// Reasons of the events emitted for the backup
+const (
+	eventBackupStarted       = "BackupStarted"
+	eventBackupSucceeded     = "BackupSucceeded"
+	eventBackupFailed        = "BackupFailed"
+	eventBackupDeleted       = "BackupDeleted"
+	eventBackupDeleteFailed  = "BackupDeleteFailed"
+	eventBackupDeleteSkipped = "BackupDeleteSkipped"
+)
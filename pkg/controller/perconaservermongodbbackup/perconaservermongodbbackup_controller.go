@@ -3,6 +3,7 @@ package perconaservermongodbbackup
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/pkg/errors"
@@ -10,6 +11,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -20,6 +22,7 @@ import (
 
 	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
 	psmdbv1 "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/metrics"
 	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/backup"
 )
 
@@ -38,7 +41,11 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcilePerconaServerMongoDBBackup{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcilePerconaServerMongoDBBackup{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor("perconaservermongodbbackup-controller"),
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -74,8 +81,9 @@ var _ reconcile.Reconciler = &ReconcilePerconaServerMongoDBBackup{}
 type ReconcilePerconaServerMongoDBBackup struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a PerconaServerMongoDBBackup object and makes changes based on the state read
@@ -101,6 +109,31 @@ func (r *ReconcilePerconaServerMongoDBBackup) Reconcile(request reconcile.Reques
 		return rr, err
 	}
 
+	if instance.DeletionTimestamp != nil {
+		// the running backup is deleted from the storage once it's
+		// finished, so its status is still tracked
+		switch instance.Status.State {
+		case psmdbv1.BackupStateRequested, psmdbv1.BackupStateRunning:
+			if api.HasFinalizer(instance, api.FinalizerDeleteBackup) {
+				err = r.reconcile(instance)
+				if err != nil {
+					return rr, errors.Wrap(err, "reconcile backup")
+				}
+			}
+		}
+
+		err = r.deleteFromStorage(instance)
+		if err != nil {
+			return rr, errors.Wrap(err, "delete backup from storage")
+		}
+		return rr, nil
+	}
+
+	err = r.reconcileFinalizer(instance)
+	if err != nil {
+		return rr, err
+	}
+
 	err = instance.CheckFields()
 	if err != nil {
 		return rr, errors.Wrap(err, "fields check")
@@ -129,8 +162,16 @@ func (r *ReconcilePerconaServerMongoDBBackup) reconcile(cr *psmdbv1.PerconaServe
 			status.Error = err.Error()
 			log.Error(err, "failed to make restore", "backup", cr.Name)
 		}
+		finished := false
 		if cr.Status.State != status.State {
+			r.stateEvent(cr, status)
+			finished = status.State == psmdbv1.BackupStateReady || status.State == psmdbv1.BackupStateError
+		}
+		if cr.Status.State != status.State || !reflect.DeepEqual(cr.Status.Replsets, status.Replsets) {
 			cr.Status = status
+			if finished {
+				metrics.ObserveBackup(cr)
+			}
 			uerr := r.updateStatus(cr)
 			if uerr != nil {
 				log.Error(uerr, "failed to updated restore status", "backup", cr.Name)
@@ -148,16 +189,31 @@ func (r *ReconcilePerconaServerMongoDBBackup) reconcile(cr *psmdbv1.PerconaServe
 		return fmt.Errorf("failed to run backup on cluster with status %s", cluster.Status.State)
 	}
 
-	cjobs, err := backup.HasActiveJobs(r.client, cr.Spec.PSMDBCluster, cr.Namespace, backup.Job{Name: cr.Name, Type: backup.TypeBackup})
-	if err != nil {
-		return errors.Wrap(err, "check for concurrent jobs")
-	}
-	if cjobs {
-		if status.State != psmdbv1.BackupStateWaiting {
-			log.Info("Waiting to finish another backup/restore.")
+	// the requested backup is already sent to agents,
+	// so only the new one waits for the others
+	if cr.Status.State == psmdbv1.BackupStateNew || cr.Status.State == psmdbv1.BackupStateWaiting {
+		var cjobs bool
+		cjobs, err = backup.HasActiveJobs(r.client, cr.Spec.PSMDBCluster, cr.Namespace, backup.Job{Name: cr.Name, Type: backup.TypeBackup})
+		if err != nil {
+			return errors.Wrap(err, "check for concurrent jobs")
+		}
+		if cjobs {
+			if status.State != psmdbv1.BackupStateWaiting {
+				log.Info("Waiting to finish another backup/restore.")
+			}
+			status.State = psmdbv1.BackupStateWaiting
+			return nil
+		}
+
+		// every replset should be backed up by its own agent
+		err = backup.CheckAgents(r.client, cluster)
+		if err != nil {
+			if status.State != psmdbv1.BackupStateWaiting {
+				log.Info("Waiting for backup agents", "reason", err.Error())
+			}
+			status.State = psmdbv1.BackupStateWaiting
+			return nil
 		}
-		status.State = psmdbv1.BackupStateWaiting
-		return nil
 	}
 
 	bcp, err := r.newBackup(cr)
@@ -175,6 +231,18 @@ func (r *ReconcilePerconaServerMongoDBBackup) reconcile(cr *psmdbv1.PerconaServe
 	return err
 }
 
+// stateEvent emits the event if the backup has started or finished
+func (r *ReconcilePerconaServerMongoDBBackup) stateEvent(cr *psmdbv1.PerconaServerMongoDBBackup, status psmdbv1.PerconaServerMongoDBBackupStatus) {
+	switch status.State {
+	case psmdbv1.BackupStateRequested:
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, eventBackupStarted, "Backup %s to storage %s started", status.PBMname, status.StorageName)
+	case psmdbv1.BackupStateReady:
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, eventBackupSucceeded, "Backup %s succeeded", status.PBMname)
+	case psmdbv1.BackupStateError:
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, eventBackupFailed, "Backup failed: %s", status.Error)
+	}
+}
+
 func (r *ReconcilePerconaServerMongoDBBackup) updateStatus(cr *psmdbv1.PerconaServerMongoDBBackup) error {
 	err := r.client.Status().Update(context.TODO(), cr)
 	if err != nil {
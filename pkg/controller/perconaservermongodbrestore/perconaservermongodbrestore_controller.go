@@ -3,6 +3,8 @@ package perconaservermongodbrestore
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/percona/percona-backup-mongodb/pbm"
@@ -12,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -34,7 +37,11 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcilePerconaServerMongoDBRestore{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcilePerconaServerMongoDBRestore{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor("perconaservermongodbrestore-controller"),
+	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -69,8 +76,9 @@ var _ reconcile.Reconciler = &ReconcilePerconaServerMongoDBRestore{}
 type ReconcilePerconaServerMongoDBRestore struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a PerconaServerMongoDBRestore object and makes changes based on the state read
@@ -125,6 +133,9 @@ func (r *ReconcilePerconaServerMongoDBRestore) reconcileRestore(cr *psmdbv1.Perc
 			log.Error(err, "failed to make restore", "name", cr.Name, "backup", cr.Spec.BackupName)
 		}
 		if cr.Status.State != status.State {
+			r.stateEvent(cr, status)
+		}
+		if cr.Status.State != status.State || !reflect.DeepEqual(cr.Status.Replsets, status.Replsets) {
 			cr.Status = status
 			uerr := r.updateStatus(cr)
 			if uerr != nil {
@@ -167,13 +178,28 @@ func (r *ReconcilePerconaServerMongoDBRestore) reconcileRestore(cr *psmdbv1.Perc
 		return errors.Wrapf(err, "get cluster %s/%s", cr.Namespace, cr.Spec.ClusterName)
 	}
 
-	pbmc, errPBM := backup.NewPBM(r.client, cluster)
+	err = cluster.Spec.Backup.SetStoragesDefaults()
+	if err != nil {
+		return errors.Wrap(err, "check backup storages")
+	}
+
+	if status.State == psmdbv1.RestoreStateNew || status.State == psmdbv1.RestoreStateWaiting {
+		// every replset should be restored by its own agent
+		err = backup.CheckAgents(r.client, cluster)
+		if err != nil {
+			log.Info("Waiting for backup agents", "reason", err.Error())
+			status.State = psmdbv1.RestoreStateWaiting
+			return nil
+		}
+	}
+
+	pbms, errPBM := backup.NewPBMs(r.client, cluster)
 	if errPBM != nil {
 		log.Info("Waiting for pbm-agent.")
 		status.State = psmdbv1.RestoreStateWaiting
 		return nil
 	}
-	defer pbmc.Close()
+	defer backup.ClosePBMs(pbms)
 
 	if status.State == psmdbv1.RestoreStateNew || status.State == psmdbv1.RestoreStateWaiting {
 		stg, ok := cluster.Spec.Backup.Storages[storageName]
@@ -181,58 +207,134 @@ func (r *ReconcilePerconaServerMongoDBRestore) reconcileRestore(cr *psmdbv1.Perc
 			return errors.Errorf("unable to get storage '%s'", cr.Spec.StorageName)
 		}
 
-		status.PBMname, err = runRestore(bcpName, stg, pbmc)
+		status.PBMname, err = runRestore(bcpName, stg, pbms)
 		status.State = psmdbv1.RestoreStateRequested
+		status.Replsets = nil
+		for _, rs := range cluster.Spec.Replsets {
+			status.Replsets = append(status.Replsets, psmdbv1.RestoreReplsetStatus{
+				Name:  rs.Name,
+				State: psmdbv1.RestoreStateRequested,
+			})
+		}
 		return err
 	}
 
-	meta, err := pbmc.C.GetRestoreMeta(cr.Status.PBMname)
-	if err != nil {
-		return errors.Wrap(err, "get pbm metadata")
+	// the restore of separately restored replsets is running
+	// until all of them are finished, and it's failed if any
+	// of them is failed
+	metas := make([]*pbm.RestoreMeta, 0, len(pbms))
+	for _, pbmc := range pbms {
+		meta, err := pbmc.C.GetRestoreMeta(cr.Status.PBMname)
+		if err != nil {
+			return replsetErr(pbmc, errors.Wrap(err, "get pbm metadata"))
+		}
+		if meta == nil || meta.Name == "" {
+			log.Info("No restore found", "PBM name", cr.Status.PBMname, "restore", cr.Name, "backup", cr.Spec.BackupName, "replset", pbmc.Replset())
+			return nil
+		}
+		metas = append(metas, meta)
 	}
 
-	if meta == nil || meta.Name == "" {
-		log.Info("No restore found", "PBM name", cr.Status.PBMname, "restore", cr.Name, "backup", cr.Spec.BackupName)
-		return nil
+	var lastTS int64
+	var errs []string
+	running := false
+	for i, meta := range metas {
+		if meta.LastTransitionTS > lastTS {
+			lastTS = meta.LastTransitionTS
+		}
+
+		switch meta.Status {
+		case pbm.StatusError:
+			errs = append(errs, replsetErr(pbms[i], errors.New(meta.Error)).Error())
+		case pbm.StatusDone:
+		default:
+			running = true
+		}
+
+		for _, rs := range meta.Replsets {
+			status.Replsets = setReplsetStatus(status.Replsets, psmdbv1.RestoreReplsetStatus{
+				Name:  rs.Name,
+				State: restoreState(rs.Status),
+				Error: rs.Error,
+			})
+		}
 	}
 
-	switch meta.Status {
-	case pbm.StatusError:
+	switch {
+	case running:
+		status.State = psmdbv1.RestoreStateRunning
+	case len(errs) > 0:
 		status.State = psmdbv1.RestoreStateError
-		status.Error = meta.Error
-	case pbm.StatusDone:
+		status.Error = strings.Join(errs, "; ")
+	default:
 		status.State = psmdbv1.RestoreStateReady
 		status.CompletedAt = &metav1.Time{
-			Time: time.Unix(meta.LastTransitionTS, 0),
+			Time: time.Unix(lastTS, 0),
 		}
-	case pbm.StatusStarting, pbm.StatusRunning:
-		status.State = psmdbv1.RestoreStateRunning
 	}
 
 	return nil
 }
 
-func runRestore(backup string, storage psmdbv1.BackupStorageSpec, pbmc *backup.PBM) (string, error) {
-	err := pbmc.SetConfig(storage)
-	if err != nil {
-		return "", errors.Wrap(err, "set pbm config")
+// replsetErr adds the name of the replset to the error
+// of the PBM if replsets are restored separately
+func replsetErr(pbmc *backup.PBM, err error) error {
+	if pbmc.Replset() == "" {
+		return err
 	}
 
-	err = pbmc.C.ResyncBackupList()
-	if err != nil {
-		return "", errors.Wrap(err, "set resync backup list from the store")
+	return errors.Wrapf(err, "replset %s", pbmc.Replset())
+}
+
+// setReplsetStatus replaces the status of the replset in the list or adds it
+func setReplsetStatus(list []psmdbv1.RestoreReplsetStatus, rs psmdbv1.RestoreReplsetStatus) []psmdbv1.RestoreReplsetStatus {
+	for i := range list {
+		if list[i].Name == rs.Name {
+			list[i] = rs
+			return list
+		}
 	}
 
+	return append(list, rs)
+}
+
+func restoreState(s pbm.Status) psmdbv1.RestoreState {
+	switch s {
+	case pbm.StatusError:
+		return psmdbv1.RestoreStateError
+	case pbm.StatusDone:
+		return psmdbv1.RestoreStateReady
+	default:
+		return psmdbv1.RestoreStateRunning
+	}
+}
+
+// runRestore requests the restore on PBM. Separately restored
+// replsets get the restore of the same name.
+func runRestore(bcpName string, storage psmdbv1.BackupStorageSpec, pbms []*backup.PBM) (string, error) {
 	rName := time.Now().UTC().Format(time.RFC3339Nano)
-	err = pbmc.C.SendCmd(pbm.Cmd{
-		Cmd: pbm.CmdRestore,
-		Restore: pbm.RestoreCmd{
-			Name:       rName,
-			BackupName: backup,
-		},
-	})
-	if err != nil {
-		return "", errors.Wrap(err, "send restore cmd")
+
+	for _, pbmc := range pbms {
+		err := pbmc.SetConfig(storage)
+		if err != nil {
+			return "", replsetErr(pbmc, errors.Wrap(err, "set pbm config"))
+		}
+
+		err = pbmc.ResyncBackupList(storage)
+		if err != nil {
+			return "", replsetErr(pbmc, errors.Wrap(err, "set resync backup list from the store"))
+		}
+
+		err = pbmc.C.SendCmd(pbm.Cmd{
+			Cmd: pbm.CmdRestore,
+			Restore: pbm.RestoreCmd{
+				Name:       rName,
+				BackupName: bcpName,
+			},
+		})
+		if err != nil {
+			return "", replsetErr(pbmc, errors.Wrap(err, "send restore cmd"))
+		}
 	}
 
 	return rName, nil
@@ -248,6 +350,18 @@ func (r *ReconcilePerconaServerMongoDBRestore) getBackup(cr *psmdbv1.PerconaServ
 	return backup, err
 }
 
+// stateEvent emits the event if the restore has started or finished
+func (r *ReconcilePerconaServerMongoDBRestore) stateEvent(cr *psmdbv1.PerconaServerMongoDBRestore, status psmdbv1.PerconaServerMongoDBRestoreStatus) {
+	switch status.State {
+	case psmdbv1.RestoreStateRequested:
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "RestoreStarted", "Restore %s started", status.PBMname)
+	case psmdbv1.RestoreStateReady:
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "RestoreSucceeded", "Restore %s succeeded", status.PBMname)
+	case psmdbv1.RestoreStateError:
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, "RestoreFailed", "Restore failed: %s", status.Error)
+	}
+}
+
 func (r *ReconcilePerconaServerMongoDBRestore) updateStatus(cr *psmdbv1.PerconaServerMongoDBRestore) error {
 	err := r.client.Status().Update(context.TODO(), cr)
 	if err != nil {
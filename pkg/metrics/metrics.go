@@ -0,0 +1,257 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+var log = logf.Log.WithName("metrics")
+
+const namespace = "psmdb"
+
+var (
+	backupDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "backup_duration_seconds",
+			Help:      "Duration of the finished backups",
+			Buckets:   prometheus.ExponentialBuckets(30, 2, 10),
+		},
+		[]string{"namespace", "cluster", "storage", "state"},
+	)
+	backupsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "backups_total",
+			Help:      "Number of the finished backups by the final state",
+		},
+		[]string{"namespace", "cluster", "storage", "state"},
+	)
+	smartUpdatePods = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "smart_update_pods",
+			Help:      "Number of the replset pods to be updated by the running smart update",
+		},
+		[]string{"namespace", "cluster", "replset"},
+	)
+	smartUpdatePodsUpdated = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "smart_update_pods_updated",
+			Help:      "Number of the replset pods already updated by the running smart update",
+		},
+		[]string{"namespace", "cluster", "replset"},
+	)
+	versionChecksTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "version_checks_total",
+			Help:      "Number of the version service checks by the result",
+		},
+		[]string{"namespace", "cluster", "result"},
+	)
+)
+
+// Register adds the operator's metrics to the registry
+// served by the manager's metrics listener
+func Register(cl client.Client) {
+	crmetrics.Registry.MustRegister(
+		&clusterCollector{client: cl},
+		backupDuration,
+		backupsTotal,
+		smartUpdatePods,
+		smartUpdatePodsUpdated,
+		versionChecksTotal,
+		prometheus.NewCounterFunc(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "mongo_pool_hits_total",
+				Help:      "Number of the requests served by the cached MongoDB connections",
+			},
+			func() float64 {
+				hits, _ := mongo.DefaultPool.Stats()
+				return float64(hits)
+			},
+		),
+		prometheus.NewCounterFunc(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "mongo_pool_misses_total",
+				Help:      "Number of the requests which needed a new MongoDB connection",
+			},
+			func() float64 {
+				_, misses := mongo.DefaultPool.Stats()
+				return float64(misses)
+			},
+		),
+	)
+}
+
+// ObserveBackup records the duration and the final state of the backup
+func ObserveBackup(bcp *api.PerconaServerMongoDBBackup) {
+	if bcp.Status.StartAt == nil {
+		return
+	}
+
+	end := bcp.Status.CompletedAt
+	if end == nil {
+		end = bcp.Status.LastTransition
+	}
+	if end == nil {
+		return
+	}
+
+	lv := []string{bcp.Namespace, bcp.Spec.PSMDBCluster, bcp.Spec.StorageName, string(bcp.Status.State)}
+	backupDuration.WithLabelValues(lv...).Observe(end.Sub(bcp.Status.StartAt.Time).Seconds())
+	backupsTotal.WithLabelValues(lv...).Inc()
+}
+
+// SmartUpdateStarted sets the number of the replset pods to be updated
+func SmartUpdateStarted(cr *api.PerconaServerMongoDB, replset string, pods int) {
+	smartUpdatePods.WithLabelValues(cr.Namespace, cr.Name, replset).Set(float64(pods))
+	smartUpdatePodsUpdated.WithLabelValues(cr.Namespace, cr.Name, replset).Set(0)
+}
+
+// SmartUpdatePodUpdated increments the number of the updated replset pods
+func SmartUpdatePodUpdated(cr *api.PerconaServerMongoDB, replset string) {
+	smartUpdatePodsUpdated.WithLabelValues(cr.Namespace, cr.Name, replset).Inc()
+}
+
+// SmartUpdateFinished removes the progress of the replset's smart update
+func SmartUpdateFinished(cr *api.PerconaServerMongoDB, replset string) {
+	smartUpdatePods.DeleteLabelValues(cr.Namespace, cr.Name, replset)
+	smartUpdatePodsUpdated.DeleteLabelValues(cr.Namespace, cr.Name, replset)
+}
+
+// VersionChecked counts the result of the version service check
+func VersionChecked(cr *api.PerconaServerMongoDB, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	versionChecksTotal.WithLabelValues(cr.Namespace, cr.Name, result).Inc()
+}
+
+var (
+	clusterStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "cluster", "state"),
+		"State of the cluster, 1 for the current state",
+		[]string{"namespace", "cluster", "state"}, nil,
+	)
+	replsetSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "replset", "size"),
+		"Desired number of the replset members",
+		[]string{"namespace", "cluster", "replset"}, nil,
+	)
+	replsetReadyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "replset", "ready"),
+		"Number of the ready replset members",
+		[]string{"namespace", "cluster", "replset"}, nil,
+	)
+	memberLagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "replset_member", "lag_seconds"),
+		"Replication lag of the replset member behind the primary",
+		[]string{"namespace", "cluster", "replset", "member"}, nil,
+	)
+	lastBackupDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "backup_task", "last_success_timestamp_seconds"),
+		"Completion time of the last successful backup of the task",
+		[]string{"namespace", "cluster", "task"}, nil,
+	)
+)
+
+var clusterStates = []api.AppState{
+	api.AppStatePending,
+	api.AppStateInit,
+	api.AppStateReady,
+	api.AppStateError,
+	api.AppStatePaused,
+}
+
+// clusterCollector reports the state of the clusters and their backups.
+// It reads the objects from the manager's cache on every scrape,
+// so there are no metrics left of the deleted clusters.
+type clusterCollector struct {
+	client client.Client
+}
+
+func (c *clusterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterStateDesc
+	ch <- replsetSizeDesc
+	ch <- replsetReadyDesc
+	ch <- memberLagDesc
+	ch <- lastBackupDesc
+}
+
+func (c *clusterCollector) Collect(ch chan<- prometheus.Metric) {
+	clusters := &api.PerconaServerMongoDBList{}
+	err := c.client.List(context.TODO(), clusters)
+	if err != nil {
+		log.Error(err, "failed to get clusters list")
+		return
+	}
+
+	bcps := &api.PerconaServerMongoDBBackupList{}
+	err = c.client.List(context.TODO(), bcps)
+	if err != nil {
+		log.Error(err, "failed to get backups list")
+		return
+	}
+
+	for _, cr := range clusters.Items {
+		for _, s := range clusterStates {
+			v := 0.0
+			if cr.Status.State == s {
+				v = 1
+			}
+			ch <- prometheus.MustNewConstMetric(clusterStateDesc, prometheus.GaugeValue, v, cr.Namespace, cr.Name, string(s))
+		}
+
+		for name, rs := range cr.Status.Replsets {
+			ch <- prometheus.MustNewConstMetric(replsetSizeDesc, prometheus.GaugeValue, float64(rs.Size), cr.Namespace, cr.Name, name)
+			ch <- prometheus.MustNewConstMetric(replsetReadyDesc, prometheus.GaugeValue, float64(rs.Ready), cr.Namespace, cr.Name, name)
+			for _, m := range rs.Members {
+				if m.State != mongo.MemberStateStrings[mongo.MemberStateSecondary] {
+					continue
+				}
+				ch <- prometheus.MustNewConstMetric(memberLagDesc, prometheus.GaugeValue, float64(m.LagSeconds), cr.Namespace, cr.Name, name, m.Name)
+			}
+		}
+
+		for _, task := range cr.Spec.Backup.Tasks {
+			last := lastTaskBackup(bcps.Items, cr, task.Name)
+			if last == nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(lastBackupDesc, prometheus.GaugeValue,
+				float64(last.Status.CompletedAt.Unix()), cr.Namespace, cr.Name, task.Name)
+		}
+	}
+}
+
+// lastTaskBackup returns the last successful backup of the cluster's task
+func lastTaskBackup(bcps []api.PerconaServerMongoDBBackup, cr api.PerconaServerMongoDB, task string) *api.PerconaServerMongoDBBackup {
+	var last *api.PerconaServerMongoDBBackup
+	for i := range bcps {
+		b := &bcps[i]
+		if b.Namespace != cr.Namespace || b.Labels["cluster"] != cr.Name || b.Labels["ancestor"] != task {
+			continue
+		}
+		if b.Status.State != api.BackupStateReady || b.Status.CompletedAt == nil {
+			continue
+		}
+		if last == nil || b.Status.CompletedAt.After(last.Status.CompletedAt.Time) {
+			last = b
+		}
+	}
+
+	return last
+}
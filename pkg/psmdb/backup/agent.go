@@ -1,6 +1,7 @@
 package backup
 
 import (
+	"sort"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -62,5 +63,64 @@ func AgentContainer(cr *api.PerconaServerMongoDB, replsetName string, replsetSiz
 		},
 		SecurityContext: cr.Spec.Backup.ContainerSecurityContext,
 		Resources:       res,
+		VolumeMounts:    agentVolumeMounts(cr, replsetName),
 	}, nil
 }
+
+// AgentVolumes returns volumes of the filesystem backup storages
+// which should be added to the pod with the backup agent. The same
+// claim is mounted into all pods, so it should be ReadWriteMany.
+func AgentVolumes(cr *api.PerconaServerMongoDB) []corev1.Volume {
+	var volumes []corev1.Volume
+	for _, name := range fsStorageNames(cr) {
+		stg := cr.Spec.Backup.Storages[name].Filesystem
+		volumes = append(volumes, corev1.Volume{
+			Name: fsStorageVolumeName(name),
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: stg.PersistentVolumeClaim,
+				NFS:                   stg.NFS,
+			},
+		})
+	}
+
+	return volumes
+}
+
+// agentVolumeMounts returns mounts of the filesystem backup storages.
+// Separately backed up replsets get their own directory of the volume,
+// so they don't overwrite each other's backups metadata.
+func agentVolumeMounts(cr *api.PerconaServerMongoDB, replsetName string) []corev1.VolumeMount {
+	subPath := ""
+	if separateReplsets(cr) {
+		subPath = replsetName
+	}
+
+	var mounts []corev1.VolumeMount
+	for _, name := range fsStorageNames(cr) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      fsStorageVolumeName(name),
+			MountPath: cr.Spec.Backup.Storages[name].Filesystem.Path,
+			SubPath:   subPath,
+		})
+	}
+
+	return mounts
+}
+
+// fsStorageNames returns sorted names of the filesystem storages
+// so the pod spec isn't changed between reconciles
+func fsStorageNames(cr *api.PerconaServerMongoDB) []string {
+	var names []string
+	for name, stg := range cr.Spec.Backup.Storages {
+		if stg.Type == api.BackupStorageFilesystem && stg.Filesystem != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func fsStorageVolumeName(storageName string) string {
+	return "backup-" + storageName
+}
@@ -0,0 +1,63 @@
+package backup
+
+import (
+	"testing"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+func TestAgentVolumeMounts(t *testing.T) {
+	storages := map[string]api.BackupStorageSpec{
+		"fs": {
+			Type:       api.BackupStorageFilesystem,
+			Filesystem: &api.BackupStorageFilesystemSpec{Path: "/backups/fs"},
+		},
+		"s3": {
+			Type: api.BackupStorageS3,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		sharding    bool
+		replsets    []string
+		wantSubPath string
+	}{
+		{
+			name:     "single replset",
+			replsets: []string{"rs0"},
+		},
+		{
+			name:     "sharded cluster",
+			sharding: true,
+			replsets: []string{"rs0", "rs1", "cfg"},
+		},
+		{
+			name:        "non-sharded cluster with several replsets",
+			replsets:    []string{"rs0", "rs1"},
+			wantSubPath: "rs0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &api.PerconaServerMongoDB{}
+			cr.Spec.Sharding.Enabled = tt.sharding
+			cr.Spec.Backup.Storages = storages
+			for _, name := range tt.replsets {
+				cr.Spec.Replsets = append(cr.Spec.Replsets, &api.ReplsetSpec{Name: name})
+			}
+
+			mounts := agentVolumeMounts(cr, tt.replsets[0])
+			if len(mounts) != 1 {
+				t.Fatalf("got %d mounts, want 1", len(mounts))
+			}
+			if mounts[0].MountPath != "/backups/fs" {
+				t.Errorf("got mount path %q, want %q", mounts[0].MountPath, "/backups/fs")
+			}
+			if mounts[0].SubPath != tt.wantSubPath {
+				t.Errorf("got sub path %q, want %q", mounts[0].SubPath, tt.wantSubPath)
+			}
+		})
+	}
+}
@@ -1,72 +1,72 @@
 package backup
 
 import (
-	batchv1 "k8s.io/api/batch/v1"
-	batchv1b "k8s.io/api/batch/v1beta1"
-	corev1 "k8s.io/api/core/v1"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
 )
 
-func BackupCronJob(backup *api.BackupTaskSpec, crName, namespace string, backupSpec api.BackupSpec, imagePullSecrets []corev1.LocalObjectReference) *batchv1b.CronJob {
-	backupPod := corev1.PodSpec{
-		RestartPolicy:      corev1.RestartPolicyNever,
-		ImagePullSecrets:   imagePullSecrets,
-		ServiceAccountName: backupSpec.ServiceAccountName,
-		Containers: []corev1.Container{
-			{
-				Name:    "backup",
-				Image:   backupSpec.Image,
-				Command: []string{"sh"},
-				Env: []corev1.EnvVar{
-					{
-						Name:  "psmdbCluster",
-						Value: crName,
-					},
-					{
-						Name: "NAMESPACE",
-						ValueFrom: &corev1.EnvVarSource{
-							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "metadata.namespace",
-							},
-						},
-					},
-				},
-				Args:            newBackupCronJobContainerArgs(backup),
-				SecurityContext: backupSpec.ContainerSecurityContext,
+// NewScheduledBackup returns a backup object of the task run at the given time
+func NewScheduledBackup(cr *api.PerconaServerMongoDB, task *api.BackupTaskSpec, t time.Time) *api.PerconaServerMongoDBBackup {
+	clusterName := cr.Name
+	if len(clusterName) > 16 {
+		clusterName = clusterName[:16]
+	}
+
+	return &api.PerconaServerMongoDBBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "cron-" + clusterName + "-" + t.UTC().Format("20060102150405") + "-",
+			Namespace:    cr.Namespace,
+			Labels: map[string]string{
+				"ancestor": task.Name,
+				"cluster":  cr.Name,
+				"type":     "cron",
 			},
 		},
-		SecurityContext: backupSpec.PodSecurityContext,
+		Spec: api.PerconaServerMongoDBBackupSpec{
+			PSMDBCluster: cr.Name,
+			StorageName:  task.StorageName,
+			Comperssion:  task.CompressionType,
+		},
 	}
+}
 
-	return &batchv1b.CronJob{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: "batch/v1beta1",
-			Kind:       "CronJob",
-		},
+// NewFinalBackup returns a backup object made before the cluster is deleted.
+// It isn't owned by the cluster, so it outlives the cluster.
+func NewFinalBackup(cr *api.PerconaServerMongoDB, spec *api.FinalBackupSpec) *api.PerconaServerMongoDBBackup {
+	clusterName := cr.Name
+	if len(clusterName) > 16 {
+		clusterName = clusterName[:16]
+	}
+
+	return &api.PerconaServerMongoDBBackup{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      crName + "-backup-" + backup.Name,
-			Namespace: namespace,
-			Labels:    NewBackupCronJobLabels(crName),
+			GenerateName: "final-" + clusterName + "-",
+			Namespace:    cr.Namespace,
+			Labels:       FinalBackupLabels(cr),
 		},
-		Spec: batchv1b.CronJobSpec{
-			Schedule:          backup.Schedule,
-			ConcurrencyPolicy: batchv1b.ForbidConcurrent,
-			JobTemplate: batchv1b.JobTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: NewBackupCronJobLabels(crName),
-				},
-				Spec: batchv1.JobSpec{
-					Template: corev1.PodTemplateSpec{
-						Spec: backupPod,
-					},
-				},
-			},
+		Spec: api.PerconaServerMongoDBBackupSpec{
+			PSMDBCluster: cr.Name,
+			StorageName:  spec.StorageName,
+			Comperssion:  spec.CompressionType,
 		},
 	}
 }
 
+// FinalBackupLabels returns labels of the cluster's final backup. The
+// cluster's UID tells it from the one of the deleted cluster with the same name.
+func FinalBackupLabels(cr *api.PerconaServerMongoDB) map[string]string {
+	return map[string]string{
+		"cluster":     cr.Name,
+		"cluster-uid": string(cr.UID),
+		"type":        "final",
+	}
+}
+
+// NewBackupCronJobLabels returns labels of the CronJobs
+// which were used to schedule backups by the previous versions
 func NewBackupCronJobLabels(crName string) map[string]string {
 	return map[string]string{
 		"app.kubernetes.io/name":       "percona-server-mongodb",
@@ -77,32 +77,3 @@ func NewBackupCronJobLabels(crName string) map[string]string {
 		"app.kubernetes.io/part-of":    "percona-server-mongodb",
 	}
 }
-
-func newBackupCronJobContainerArgs(backup *api.BackupTaskSpec) []string {
-	return []string{
-		"-c",
-		`curl \
-			-vvv \
-			-X POST \
-			--cacert /run/secrets/kubernetes.io/serviceaccount/ca.crt \
-			-H "Content-Type: application/json" \
-			-H "Authorization: Bearer $(cat /run/secrets/kubernetes.io/serviceaccount/token)" \
-			--data "{ 
-				\"kind\":\"PerconaServerMongoDBBackup\",
-				\"apiVersion\":\"psmdb.percona.com/v1\",
-				\"metadata\":{
-					\"generateName\":\"cron-${psmdbCluster:0:16}-$(date -u "+%Y%m%d%H%M%S")-\",
-					\"labels\":{
-						\"ancestor\":\"` + backup.Name + `\",
-						\"cluster\":\"${psmdbCluster}\",
-						\"type\":\"cron\"
-					}
-				},
-				\"spec\":{
-					\"psmdbCluster\":\"${psmdbCluster}\",
-					\"storageName\":\"` + backup.StorageName + `\"
-				}
-			}" \
-			https://${KUBERNETES_SERVICE_HOST}:${KUBERNETES_SERVICE_PORT}/apis/psmdb.percona.com/v1/namespaces/${NAMESPACE}/perconaservermongodbbackups`,
-	}
-}
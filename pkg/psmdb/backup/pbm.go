@@ -3,12 +3,12 @@ package backup
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 
-	"github.com/percona/percona-backup-mongodb/pbm/storage/s3"
-
 	"github.com/percona/percona-backup-mongodb/pbm"
 	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -17,6 +17,7 @@ import (
 
 	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
 	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
 )
 
 const (
@@ -29,13 +30,45 @@ type PBM struct {
 	C         *pbm.PBM
 	k8c       client.Client
 	namespace string
+	// replset is the name of the replset the PBM backs up
+	// if replsets of the cluster are backed up separately
+	replset string
+	release func()
+}
+
+// pbmConn is the PBM connection kept in the pool
+type pbmConn struct {
+	*pbm.PBM
+}
+
+func (c pbmConn) Ping(ctx context.Context) error {
+	return c.Conn.Ping(ctx, readpref.Primary())
+}
+
+func (c pbmConn) Close(ctx context.Context) error {
+	return c.Conn.Disconnect(ctx)
+}
+
+// separateReplsets reports whether replsets of the cluster are backed up
+// separately. Replsets of the non-sharded cluster are separate deployments
+// which don't share the PBM control collections, so each of them has its own PBM.
+func separateReplsets(cluster *api.PerconaServerMongoDB) bool {
+	return !cluster.Spec.Sharding.Enabled && len(cluster.Spec.Replsets) > 1
 }
 
-// NewPBM creates a new connection to PBM.
-// It should be closed after the last use with.
-func NewPBM(c client.Client, cluster *api.PerconaServerMongoDB) (*PBM, error) {
-	rs := cluster.Spec.Replsets[0]
+// controlReplset returns the replset PBM keeps its control collections in.
+// It's the config server replset for the sharded cluster.
+func controlReplset(cluster *api.PerconaServerMongoDB) *api.ReplsetSpec {
+	if cluster.Spec.Sharding.Enabled {
+		if rs := cluster.ConfigsvrReplset(); rs != nil {
+			return rs
+		}
+	}
+
+	return cluster.Spec.Replsets[0]
+}
 
+func replsetPods(c client.Client, cluster *api.PerconaServerMongoDB, rsName string) (*corev1.PodList, error) {
 	pods := &corev1.PodList{}
 	err := c.List(context.TODO(),
 		pods,
@@ -44,14 +77,86 @@ func NewPBM(c client.Client, cluster *api.PerconaServerMongoDB) (*PBM, error) {
 			LabelSelector: labels.SelectorFromSet(map[string]string{
 				"app.kubernetes.io/name":       "percona-server-mongodb",
 				"app.kubernetes.io/instance":   cluster.Name,
-				"app.kubernetes.io/replset":    rs.Name,
+				"app.kubernetes.io/replset":    rsName,
 				"app.kubernetes.io/managed-by": "percona-server-mongodb-operator",
 				"app.kubernetes.io/part-of":    "percona-server-mongodb",
 			}),
 		},
 	)
 	if err != nil {
-		return nil, errors.Wrapf(err, "get pods list for replset %s", rs.Name)
+		return nil, errors.Wrapf(err, "get pods list for replset %s", rsName)
+	}
+
+	return pods, nil
+}
+
+// CheckAgents returns an error if there is a replset in the cluster
+// without running backup agents
+func CheckAgents(c client.Client, cluster *api.PerconaServerMongoDB) error {
+	for _, rs := range cluster.Spec.Replsets {
+		pods, err := replsetPods(c, cluster, rs.Name)
+		if err != nil {
+			return err
+		}
+
+		if !hasRunningAgent(pods.Items) {
+			return errors.Errorf("no running backup agents in replset %s", rs.Name)
+		}
+	}
+
+	return nil
+}
+
+func hasRunningAgent(pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name == agentContainerName && cs.State.Running != nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// NewPBMs returns the pooled connections to PBM which back up the whole
+// cluster: the one of the control replset, or one per replset if they are
+// backed up separately. They should be closed after the last use with ClosePBMs.
+func NewPBMs(c client.Client, cluster *api.PerconaServerMongoDB) ([]*PBM, error) {
+	if !separateReplsets(cluster) {
+		pbmc, err := newPBM(c, cluster, controlReplset(cluster))
+		if err != nil {
+			return nil, err
+		}
+		return []*PBM{pbmc}, nil
+	}
+
+	pbms := make([]*PBM, 0, len(cluster.Spec.Replsets))
+	for _, rs := range cluster.Spec.Replsets {
+		pbmc, err := newPBM(c, cluster, rs)
+		if err != nil {
+			ClosePBMs(pbms)
+			return nil, errors.Wrapf(err, "replset %s", rs.Name)
+		}
+		pbmc.replset = rs.Name
+		pbms = append(pbms, pbmc)
+	}
+
+	return pbms, nil
+}
+
+// ClosePBMs closes the PBM connections
+func ClosePBMs(pbms []*PBM) {
+	for _, pbmc := range pbms {
+		pbmc.Close()
+	}
+}
+
+// newPBM returns the pooled connection to PBM through the given replset
+func newPBM(c client.Client, cluster *api.PerconaServerMongoDB, rs *api.ReplsetSpec) (*PBM, error) {
+	pods, err := replsetPods(c, cluster, rs.Name)
+	if err != nil {
+		return nil, err
 	}
 	usersSecretName := cluster.Spec.Secrets.Users
 	if cluster.CompareVersion("1.5.0") >= 0 {
@@ -76,23 +181,31 @@ func NewPBM(c client.Client, cluster *api.PerconaServerMongoDB) (*PBM, error) {
 		strings.Join(addrs, ","),
 	)
 
-	pbmc, err := pbm.New(context.Background(), murl, "operator-pbm-ctl")
+	slot := cluster.Namespace + "/" + cluster.Name + "/pbm/" + rs.Name
+	conn, release, err := mongo.DefaultPool.Get(slot, mongo.Key(murl), func() (mongo.Conn, error) {
+		pbmc, err := pbm.New(context.Background(), murl, "operator-pbm-ctl")
+		if err != nil {
+			return nil, err
+		}
+		return pbmConn{pbmc}, nil
+	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "create PBM connection to %s", strings.Join(addrs, ","))
 	}
 
 	return &PBM{
-		C:         pbmc,
+		C:         conn.(pbmConn).PBM,
 		k8c:       c,
 		namespace: cluster.Namespace,
+		release:   release,
 	}, nil
 }
 
 // SetConfig sets the pbm config with storage defined in the cluster CR
 // by given storageName
 func (b *PBM) SetConfig(stg api.BackupStorageSpec) error {
-	switch stg.Type {
-	case api.BackupStorageS3:
+	var creds *corev1.Secret
+	if stg.Type == api.BackupStorageS3 {
 		if stg.S3.CredentialsSecret == "" {
 			return errors.New("no credentials specified for the secret name")
 		}
@@ -100,37 +213,49 @@ func (b *PBM) SetConfig(stg api.BackupStorageSpec) error {
 		if err != nil {
 			return errors.Wrap(err, "getting s3 credentials secret name")
 		}
-		conf := pbm.Config{
-			Storage: pbm.StorageConf{
-				Type: pbm.StorageS3,
-				S3: s3.Conf{
-					Region:      stg.S3.Region,
-					EndpointURL: stg.S3.EndpointURL,
-					Bucket:      stg.S3.Bucket,
-					Prefix:      stg.S3.Prefix,
-					Credentials: s3.Credentials{
-						AccessKeyID:     string(s3secret.Data[awsAccessKeySecretKey]),
-						SecretAccessKey: string(s3secret.Data[awsSecretAccessKeySecretKey]),
-					},
-				},
-			},
-		}
-		err = b.C.SetConfig(conf)
-		if err != nil {
-			return errors.Wrap(err, "write config")
-		}
-	case api.BackupStorageFilesystem:
-		return errors.New("filesystem backup storage not supported yet, skipping storage name")
-	default:
-		return errors.New("unsupported backup storage type")
+		creds = s3secret
+	}
+
+	conf, err := storageConf(stg, creds)
+	if err != nil {
+		return err
+	}
+	// separately backed up replsets would overwrite each other's
+	// metadata, so each of them gets its own directory. The filesystem
+	// one is mounted into agents of the replset, see agentVolumeMounts.
+	if b.replset != "" && conf.Type == pbm.StorageS3 {
+		conf.S3.Prefix = path.Join(conf.S3.Prefix, b.replset)
+	}
+
+	err = b.C.SetConfig(pbm.Config{Storage: conf})
+	if err != nil {
+		return errors.Wrap(err, "write config")
 	}
 
 	return nil
 }
 
+// ResyncBackupList updates the backups metadata from the storage.
+// Some storages are reachable only by agents, so they are asked
+// to do it in this case.
+func (b *PBM) ResyncBackupList(stg api.BackupStorageSpec) error {
+	if !stg.Type.OperatorAccessible() {
+		return errors.Wrap(b.C.SendCmd(pbm.Cmd{Cmd: pbm.CmdResyncBackupList}), "send resync cmd")
+	}
+
+	return b.C.ResyncBackupList()
+}
+
+// Replset returns the name of the replset the PBM backs up if replsets
+// of the cluster are backed up separately and empty string otherwise
+func (b *PBM) Replset() string {
+	return b.replset
+}
+
 // Close close the PBM connection
 func (b *PBM) Close() error {
-	return b.C.Conn.Disconnect(context.Background())
+	b.release()
+	return nil
 }
 
 func secret(cl client.Client, namespace, secretName string) (*corev1.Secret, error) {
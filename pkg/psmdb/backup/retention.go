@@ -0,0 +1,96 @@
+package backup
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+// BackupsToPrune returns finished backups of the task which are out of
+// its retention policy, the oldest ones first. Only ready backups count
+// towards keep, the failed ones are pruned by their age.
+func BackupsToPrune(task api.BackupTaskSpec, bcps []api.PerconaServerMongoDBBackup, now time.Time) []api.PerconaServerMongoDBBackup {
+	if !task.HasRetention() {
+		return nil
+	}
+
+	done := make([]api.PerconaServerMongoDBBackup, 0, len(bcps))
+	for _, b := range bcps {
+		switch b.Status.State {
+		case api.BackupStateReady, api.BackupStateError:
+			done = append(done, b)
+		}
+	}
+
+	// newest first
+	sort.Slice(done, func(i, j int) bool {
+		return done[j].CreationTimestamp.Before(&done[i].CreationTimestamp)
+	})
+
+	var prune []api.PerconaServerMongoDBBackup
+	ready := 0
+	for _, b := range done {
+		expired := task.MaxAge != nil && b.CreationTimestamp.Add(task.MaxAge.Duration).Before(now)
+		if b.Status.State == api.BackupStateError {
+			if expired {
+				prune = append(prune, b)
+			}
+			continue
+		}
+
+		if (task.Keep > 0 && ready >= task.Keep) || expired {
+			prune = append(prune, b)
+		}
+		ready++
+	}
+
+	for i, j := 0, len(prune)-1; i < j; i, j = i+1, j-1 {
+		prune[i], prune[j] = prune[j], prune[i]
+	}
+
+	return prune
+}
+
+// DeleteBackup deletes the backup metadata and files from the given storage.
+// The storages reachable only by agents aren't supported as the agents
+// of the bundled PBM v1.2.0 can't delete backups.
+func (b *PBM) DeleteBackup(name string, stg api.BackupStorageSpec) error {
+	if !stg.Type.OperatorAccessible() {
+		return errors.Errorf("deleting backups from %s storage isn't supported by the bundled PBM v1.2.0", stg.Type)
+	}
+
+	meta, err := b.C.GetBackupMeta(name)
+	if err != nil {
+		return errors.Wrap(err, "get backup meta")
+	}
+	if meta.Name == "" {
+		// already deleted
+		return nil
+	}
+
+	err = b.SetConfig(stg)
+	if err != nil {
+		return errors.Wrap(err, "set pbm config")
+	}
+
+	return b.C.DeleteBackup(name)
+}
+
+// DeleteClusterBackup deletes the backup through every PBM of the cluster,
+// see NewPBMs
+func DeleteClusterBackup(pbms []*PBM, name string, stg api.BackupStorageSpec) error {
+	for _, pbmc := range pbms {
+		err := pbmc.DeleteBackup(name, stg)
+		if err != nil && pbmc.replset != "" {
+			return errors.Wrapf(err, "replset %s", pbmc.replset)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
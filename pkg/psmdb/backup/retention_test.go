@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+func TestBackupsToPrune(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	bcp := func(name string, age time.Duration, state api.BackupState) api.PerconaServerMongoDBBackup {
+		return api.PerconaServerMongoDBBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Status: api.PerconaServerMongoDBBackupStatus{State: state},
+		}
+	}
+	day := 24 * time.Hour
+	maxAge := &metav1.Duration{Duration: 3 * day}
+
+	tests := []struct {
+		name string
+		task api.BackupTaskSpec
+		bcps []api.PerconaServerMongoDBBackup
+		want []string
+	}{
+		{
+			name: "no retention",
+			bcps: []api.PerconaServerMongoDBBackup{
+				bcp("old", 10*day, api.BackupStateReady),
+			},
+		},
+		{
+			name: "keep",
+			task: api.BackupTaskSpec{Keep: 2},
+			bcps: []api.PerconaServerMongoDBBackup{
+				bcp("b2", 2*day, api.BackupStateReady),
+				bcp("b4", 4*day, api.BackupStateReady),
+				bcp("b1", 1*day, api.BackupStateReady),
+				bcp("b3", 3*day, api.BackupStateReady),
+			},
+			want: []string{"b4", "b3"},
+		},
+		{
+			name: "max age",
+			task: api.BackupTaskSpec{MaxAge: maxAge},
+			bcps: []api.PerconaServerMongoDBBackup{
+				bcp("b1", 1*day, api.BackupStateReady),
+				bcp("b5", 5*day, api.BackupStateReady),
+				bcp("b4", 4*day, api.BackupStateReady),
+			},
+			want: []string{"b5", "b4"},
+		},
+		{
+			name: "keep and max age",
+			task: api.BackupTaskSpec{Keep: 3, MaxAge: maxAge},
+			bcps: []api.PerconaServerMongoDBBackup{
+				bcp("b1", 1*day, api.BackupStateReady),
+				bcp("b2", 2*day, api.BackupStateReady),
+				bcp("b4", 4*day, api.BackupStateReady),
+				bcp("b5", 5*day, api.BackupStateReady),
+			},
+			want: []string{"b5", "b4"},
+		},
+		{
+			name: "mixed states",
+			task: api.BackupTaskSpec{Keep: 1, MaxAge: maxAge},
+			bcps: []api.PerconaServerMongoDBBackup{
+				bcp("running", 5*day, api.BackupStateRunning),
+				bcp("requested", 4*day, api.BackupStateRequested),
+				bcp("failed-new", 1*day, api.BackupStateError),
+				bcp("failed-old", 4*day, api.BackupStateError),
+				bcp("ready-new", 2*day, api.BackupStateReady),
+				bcp("ready-old", 2*day+time.Hour, api.BackupStateReady),
+			},
+			want: []string{"failed-old", "ready-old"},
+		},
+		{
+			name: "failed backups aren't kept",
+			task: api.BackupTaskSpec{Keep: 2},
+			bcps: []api.PerconaServerMongoDBBackup{
+				bcp("failed", 1*day, api.BackupStateError),
+				bcp("b2", 2*day, api.BackupStateReady),
+				bcp("b3", 3*day, api.BackupStateReady),
+				bcp("b4", 4*day, api.BackupStateReady),
+			},
+			want: []string{"b4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, b := range BackupsToPrune(tt.task, tt.bcps, now) {
+				got = append(got, b.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BackupsToPrune() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
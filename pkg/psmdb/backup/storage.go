@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"github.com/percona/percona-backup-mongodb/pbm"
+	"github.com/percona/percona-backup-mongodb/pbm/storage/fs"
+	"github.com/percona/percona-backup-mongodb/pbm/storage/s3"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+// storageConf returns the pbm config of the storage. The credentials
+// secret is needed only for S3.
+func storageConf(stg api.BackupStorageSpec, creds *corev1.Secret) (pbm.StorageConf, error) {
+	switch stg.Type {
+	case api.BackupStorageS3:
+		if creds == nil {
+			return pbm.StorageConf{}, errors.New("no s3 credentials secret")
+		}
+		return pbm.StorageConf{
+			Type: pbm.StorageS3,
+			S3: s3.Conf{
+				Region:      stg.S3.Region,
+				EndpointURL: stg.S3.EndpointURL,
+				Bucket:      stg.S3.Bucket,
+				Prefix:      stg.S3.Prefix,
+				Credentials: s3.Credentials{
+					AccessKeyID:     string(creds.Data[awsAccessKeySecretKey]),
+					SecretAccessKey: string(creds.Data[awsSecretAccessKeySecretKey]),
+				},
+			},
+		}, nil
+	case api.BackupStorageFilesystem:
+		if stg.Filesystem == nil || stg.Filesystem.Path == "" {
+			return pbm.StorageConf{}, errors.New("no path specified for the filesystem storage")
+		}
+		return pbm.StorageConf{
+			Type: pbm.StorageFilesystem,
+			Filesystem: fs.Conf{
+				Path: stg.Filesystem.Path,
+			},
+		}, nil
+	default:
+		return pbm.StorageConf{}, errors.New("unsupported backup storage type")
+	}
+}
@@ -0,0 +1,111 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/percona/percona-backup-mongodb/pbm"
+	corev1 "k8s.io/api/core/v1"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+func TestStorageConf(t *testing.T) {
+	creds := &corev1.Secret{
+		Data: map[string][]byte{
+			awsAccessKeySecretKey:       []byte("key-id"),
+			awsSecretAccessKeySecretKey: []byte("secret-key"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		stg      api.BackupStorageSpec
+		creds    *corev1.Secret
+		wantType pbm.StorageType
+		wantErr  bool
+	}{
+		{
+			name: "s3",
+			stg: api.BackupStorageSpec{
+				Type: api.BackupStorageS3,
+				S3:   api.BackupStorageS3Spec{Bucket: "bucket", Region: "us-east-1", Prefix: "psmdb"},
+			},
+			creds:    creds,
+			wantType: pbm.StorageS3,
+		},
+		{
+			name: "s3 without credentials",
+			stg: api.BackupStorageSpec{
+				Type: api.BackupStorageS3,
+				S3:   api.BackupStorageS3Spec{Bucket: "bucket"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "filesystem",
+			stg: api.BackupStorageSpec{
+				Type:       api.BackupStorageFilesystem,
+				Filesystem: &api.BackupStorageFilesystemSpec{Path: "/backups/fs"},
+			},
+			wantType: pbm.StorageFilesystem,
+		},
+		{
+			name: "filesystem without path",
+			stg: api.BackupStorageSpec{
+				Type:       api.BackupStorageFilesystem,
+				Filesystem: &api.BackupStorageFilesystemSpec{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "azure",
+			stg: api.BackupStorageSpec{
+				Type:  api.BackupStorageAzure,
+				Azure: &api.BackupStorageAzureSpec{Container: "container", CredentialsSecret: "azure-secret"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "gcs",
+			stg: api.BackupStorageSpec{
+				Type: api.BackupStorageGCS,
+				GCS:  &api.BackupStorageGCSSpec{Bucket: "bucket", CredentialsSecret: "gcs-secret"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			stg:     api.BackupStorageSpec{Type: "ftp"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, err := storageConf(tt.stg, tt.creds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("storageConf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if conf.Type != tt.wantType {
+				t.Errorf("storageConf() type = %s, want %s", conf.Type, tt.wantType)
+			}
+
+			switch conf.Type {
+			case pbm.StorageS3:
+				if conf.S3.Bucket != tt.stg.S3.Bucket || conf.S3.Region != tt.stg.S3.Region || conf.S3.Prefix != tt.stg.S3.Prefix {
+					t.Errorf("storageConf() s3 = %+v, want the options of %+v", conf.S3, tt.stg.S3)
+				}
+				if conf.S3.Credentials.AccessKeyID != "key-id" || conf.S3.Credentials.SecretAccessKey != "secret-key" {
+					t.Errorf("storageConf() s3 credentials aren't taken from the secret")
+				}
+			case pbm.StorageFilesystem:
+				if conf.Filesystem.Path != tt.stg.Filesystem.Path {
+					t.Errorf("storageConf() path = %s, want %s", conf.Filesystem.Path, tt.stg.Filesystem.Path)
+				}
+			}
+		})
+	}
+}
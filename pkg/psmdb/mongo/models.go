@@ -17,15 +17,16 @@ type ReplsetTags map[string]string
 
 // RSMember document from 'replSetGetConfig': https://docs.mongodb.com/manual/reference/command/replSetGetConfig/#dbcmd.replSetGetConfig
 type ConfigMember struct {
-	ID           int         `bson:"_id" json:"_id"`
-	Host         string      `bson:"host" json:"host"`
-	ArbiterOnly  bool        `bson:"arbiterOnly" json:"arbiterOnly"`
-	BuildIndexes bool        `bson:"buildIndexes" json:"buildIndexes"`
-	Hidden       bool        `bson:"hidden" json:"hidden"`
-	Priority     int         `bson:"priority" json:"priority"`
-	Tags         ReplsetTags `bson:"tags,omitempty" json:"tags,omitempty"`
-	SlaveDelay   int64       `bson:"slaveDelay" json:"slaveDelay"`
-	Votes        int         `bson:"votes" json:"votes"`
+	ID           int               `bson:"_id" json:"_id"`
+	Host         string            `bson:"host" json:"host"`
+	ArbiterOnly  bool              `bson:"arbiterOnly" json:"arbiterOnly"`
+	BuildIndexes bool              `bson:"buildIndexes" json:"buildIndexes"`
+	Hidden       bool              `bson:"hidden" json:"hidden"`
+	Priority     int               `bson:"priority" json:"priority"`
+	Tags         ReplsetTags       `bson:"tags,omitempty" json:"tags,omitempty"`
+	Horizons     map[string]string `bson:"horizons,omitempty" json:"horizons,omitempty"`
+	SlaveDelay   int64             `bson:"slaveDelay" json:"slaveDelay"`
+	Votes        int               `bson:"votes" json:"votes"`
 }
 
 type ConfigMembers []ConfigMember
@@ -42,14 +43,14 @@ type RSConfig struct {
 
 // Settings document from 'replSetGetConfig': https://docs.mongodb.com/manual/reference/command/replSetGetConfig/#dbcmd.replSetGetConfig
 type Settings struct {
-	ChainingAllowed         bool                   `bson:"chainingAllowed,omitempty" json:"chainingAllowed,omitempty"`
-	HeartbeatIntervalMillis int64                  `bson:"heartbeatIntervalMillis,omitempty" json:"heartbeatIntervalMillis,omitempty"`
-	HeartbeatTimeoutSecs    int                    `bson:"heartbeatTimeoutSecs,omitempty" json:"heartbeatTimeoutSecs,omitempty"`
-	ElectionTimeoutMillis   int64                  `bson:"electionTimeoutMillis,omitempty" json:"electionTimeoutMillis,omitempty"`
-	CatchUpTimeoutMillis    int64                  `bson:"catchUpTimeoutMillis,omitempty" json:"catchUpTimeoutMillis,omitempty"`
-	GetLastErrorModes       map[string]ReplsetTags `bson:"getLastErrorModes,omitempty" json:"getLastErrorModes,omitempty"`
-	GetLastErrorDefaults    WriteConcern           `bson:"getLastErrorDefaults,omitempty" json:"getLastErrorDefaults,omitempty"`
-	ReplicaSetID            primitive.ObjectID     `bson:"replicaSetId,omitempty" json:"replicaSetId,omitempty"`
+	ChainingAllowed         *bool                     `bson:"chainingAllowed,omitempty" json:"chainingAllowed,omitempty"`
+	HeartbeatIntervalMillis int64                     `bson:"heartbeatIntervalMillis,omitempty" json:"heartbeatIntervalMillis,omitempty"`
+	HeartbeatTimeoutSecs    int                       `bson:"heartbeatTimeoutSecs,omitempty" json:"heartbeatTimeoutSecs,omitempty"`
+	ElectionTimeoutMillis   int64                     `bson:"electionTimeoutMillis,omitempty" json:"electionTimeoutMillis,omitempty"`
+	CatchUpTimeoutMillis    int64                     `bson:"catchUpTimeoutMillis,omitempty" json:"catchUpTimeoutMillis,omitempty"`
+	GetLastErrorModes       map[string]map[string]int `bson:"getLastErrorModes,omitempty" json:"getLastErrorModes,omitempty"`
+	GetLastErrorDefaults    WriteConcern              `bson:"getLastErrorDefaults,omitempty" json:"getLastErrorDefaults,omitempty"`
+	ReplicaSetID            primitive.ObjectID        `bson:"replicaSetId,omitempty" json:"replicaSetId,omitempty"`
 }
 
 // Response document from 'replSetGetConfig': https://docs.mongodb.com/manual/reference/command/replSetGetConfig/#dbcmd.replSetGetConfig
@@ -64,6 +65,27 @@ type BuildInfo struct {
 	OKResponse `bson:",inline"`
 }
 
+// DBStats is a response of the dbStats command. The sizes of the filesystem
+// the database files are stored on are reported since MongoDB 3.6.
+type DBStats struct {
+	FsUsedSize  float64 `json:"fsUsedSize" bson:"fsUsedSize"`
+	FsTotalSize float64 `json:"fsTotalSize" bson:"fsTotalSize"`
+	OKResponse  `bson:",inline"`
+}
+
+// ShardList is a response of the listShards command
+type ShardList struct {
+	Shards     []Shard `bson:"shards" json:"shards"`
+	OKResponse `bson:",inline"`
+}
+
+// Shard represents a shard of the sharded cluster
+type Shard struct {
+	ID    string `bson:"_id" json:"_id"`
+	Host  string `bson:"host" json:"host"`
+	State int    `bson:"state" json:"state"`
+}
+
 // OKResponse is a standard MongoDB response
 type OKResponse struct {
 	Errmsg string `bson:"errmsg,omitempty" json:"errmsg,omitempty"`
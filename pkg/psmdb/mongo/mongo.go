@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net"
+	"reflect"
 	"time"
 
 	"github.com/pkg/errors"
@@ -24,6 +25,9 @@ type Config struct {
 	Username    string
 	Password    string
 	TLSConf     *tls.Config
+	// Direct connects to the only host without discovering the replset,
+	// so the commands are run on the certain member
+	Direct bool
 }
 
 func Dial(conf *Config) (*mongo.Client, error) {
@@ -32,13 +36,21 @@ func Dial(conf *Config) (*mongo.Client, error) {
 
 	opts := options.Client().
 		SetHosts(conf.Hosts).
-		SetReplicaSet(conf.ReplSetName).
 		SetAuth(options.Credential{
 			Password: conf.Password,
 			Username: conf.Username,
 		}).
 		SetWriteConcern(writeconcern.New(writeconcern.WMajority(), writeconcern.J(true))).
 		SetReadPreference(readpref.Primary()).SetTLSConfig(conf.TLSConf)
+
+	// mongos doesn't belong to any replset
+	if conf.ReplSetName != "" && !conf.Direct {
+		opts.SetReplicaSet(conf.ReplSetName)
+	}
+	if conf.Direct {
+		opts.SetDirect(true)
+	}
+
 	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to mongo rs: %v", err)
@@ -147,6 +159,27 @@ func RSBuildInfo(ctx context.Context, client *mongo.Client) (BuildInfo, error) {
 	return bi, nil
 }
 
+// ReadDBStats returns the storage stats of the admin database
+// on the member the client is connected to
+func ReadDBStats(ctx context.Context, client *mongo.Client) (DBStats, error) {
+	stats := DBStats{}
+
+	resp := client.Database("admin").RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}})
+	if resp.Err() != nil {
+		return stats, errors.Wrap(resp.Err(), "dbStats")
+	}
+
+	if err := resp.Decode(&stats); err != nil {
+		return stats, errors.Wrap(err, "failed to decode db stats")
+	}
+
+	if stats.OK != 1 {
+		return stats, errors.Errorf("mongo says: %s", stats.Errmsg)
+	}
+
+	return stats, nil
+}
+
 func StepDown(ctx context.Context, client *mongo.Client) error {
 	resp := OKResponse{}
 
@@ -172,6 +205,46 @@ func StepDown(ctx context.Context, client *mongo.Client) error {
 	return nil
 }
 
+// ListShards returns shards registered in the cluster. Should be run against mongos
+func ListShards(ctx context.Context, client *mongo.Client) (ShardList, error) {
+	shards := ShardList{}
+
+	resp := client.Database("admin").RunCommand(ctx, bson.D{{Key: "listShards", Value: 1}})
+	if resp.Err() != nil {
+		return shards, errors.Wrap(resp.Err(), "listShards")
+	}
+
+	if err := resp.Decode(&shards); err != nil {
+		return shards, errors.Wrap(err, "failed to decode shard list")
+	}
+
+	if shards.OK != 1 {
+		return shards, errors.Errorf("mongo says: %s", shards.Errmsg)
+	}
+
+	return shards, nil
+}
+
+// AddShard adds replset to the sharded cluster. Should be run against mongos
+func AddShard(ctx context.Context, client *mongo.Client, rsName, host string) error {
+	resp := OKResponse{}
+
+	res := client.Database("admin").RunCommand(ctx, bson.D{{Key: "addShard", Value: rsName + "/" + host}})
+	if res.Err() != nil {
+		return errors.Wrap(res.Err(), "addShard")
+	}
+
+	if err := res.Decode(&resp); err != nil {
+		return errors.Wrap(err, "failed to decode addShard response")
+	}
+
+	if resp.OK != 1 {
+		return errors.Errorf("mongo says: %s", resp.Errmsg)
+	}
+
+	return nil
+}
+
 // UpdateUserPass updates user's password
 func UpdateUserPass(ctx context.Context, client *mongo.Client, name, pass string) error {
 	return client.Database("admin").RunCommand(ctx, bson.D{{Key: "updateUser", Value: name}, {Key: "pwd", Value: pass}}).Err()
@@ -231,6 +304,18 @@ func (m *ConfigMembers) RemoveOld(compareWith ConfigMembers) (changes bool) {
 	return changes
 }
 
+// Remove removes the member with the given host
+func (m *ConfigMembers) Remove(host string) (changes bool) {
+	for i, member := range *m {
+		if member.Host == host {
+			*m = append([]ConfigMember(*m)[:i], []ConfigMember(*m)[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
 // AddNew adds new members from given list
 func (m *ConfigMembers) AddNew(from ConfigMembers) (changes bool) {
 	cm := make(map[string]struct{}, len(*m))
@@ -255,6 +340,63 @@ func (m *ConfigMembers) AddNew(from ConfigMembers) (changes bool) {
 	return changes
 }
 
+// SetHorizons sets the hosts and horizons of the members to the ones of the
+// same members from the given list. The members are matched by the host or
+// by a horizon, so the member added with its external address gets
+// the internal host and keeps the external one as the horizon.
+func (m *ConfigMembers) SetHorizons(from ConfigMembers) (changes bool) {
+	cm := make(map[string]ConfigMember, len(from))
+	for _, member := range from {
+		cm[member.Host] = member
+		for _, h := range member.Horizons {
+			if _, ok := cm[h]; !ok {
+				cm[h] = member
+			}
+		}
+	}
+
+	for i, member := range *m {
+		d, ok := cm[member.Host]
+		if !ok {
+			continue
+		}
+		if member.Host != d.Host || !reflect.DeepEqual(member.Horizons, d.Horizons) {
+			[]ConfigMember(*m)[i].Host = d.Host
+			[]ConfigMember(*m)[i].Horizons = d.Horizons
+			changes = true
+		}
+	}
+
+	return changes
+}
+
+// SetPoolMembers brings the hidden members to the options of the same
+// members from the given list, e.g. after the delay of the pool is changed
+func (m *ConfigMembers) SetPoolMembers(from ConfigMembers) (changes bool) {
+	cm := make(map[string]ConfigMember, len(from))
+	for _, member := range from {
+		if member.Hidden {
+			cm[member.Host] = member
+		}
+	}
+
+	for i, member := range *m {
+		d, ok := cm[member.Host]
+		if !ok {
+			continue
+		}
+		if member.Hidden != d.Hidden || member.Priority != d.Priority || member.Votes != d.Votes || member.SlaveDelay != d.SlaveDelay {
+			[]ConfigMember(*m)[i].Hidden = d.Hidden
+			[]ConfigMember(*m)[i].Priority = d.Priority
+			[]ConfigMember(*m)[i].Votes = d.Votes
+			[]ConfigMember(*m)[i].SlaveDelay = d.SlaveDelay
+			changes = true
+		}
+	}
+
+	return changes
+}
+
 // SetVotes sets voting parameters for members list
 func (m *ConfigMembers) SetVotes() {
 	votes := 0
@@ -287,6 +429,20 @@ func (m *ConfigMembers) SetVotes() {
 	}
 }
 
+// VotingMajority returns the majority of the replset's voting members for
+// the given number of members which can vote. As SetVotes does, the voters
+// are capped by MaxVotingMembers and an even number of them is made odd.
+func VotingMajority(voters int32) int32 {
+	if voters > MaxVotingMembers {
+		voters = MaxVotingMembers
+	}
+	if voters%2 == 0 && voters > 0 {
+		voters--
+	}
+
+	return voters/2 + 1
+}
+
 func (m ConfigMember) String() string {
 	return fmt.Sprintf("{votes: %d, priority: %d}", m.Votes, m.Priority)
 }
@@ -295,3 +295,75 @@ func TestVoting(t *testing.T) {
 		}
 	}
 }
+
+func TestSetPoolMembers(t *testing.T) {
+	mset := mongo.ConfigMembers{
+		{Host: "rs0-0", Votes: 1, Priority: 1},
+		{Host: "rs0-delayed-0", Hidden: true, SlaveDelay: 3600},
+	}
+	desired := mongo.ConfigMembers{
+		{Host: "rs0-0"},
+		{Host: "rs0-delayed-0", Hidden: true, SlaveDelay: 7200},
+	}
+
+	if !mset.SetPoolMembers(desired) {
+		t.Fatal("the changed delay should be applied")
+	}
+	if mset[0].Votes != 1 || mset[0].Priority != 1 {
+		t.Errorf("the voting member shouldn't be changed, have %v", mset[0])
+	}
+	if mset[1].SlaveDelay != 7200 || mset[1].Votes != 0 || mset[1].Priority != 0 {
+		t.Errorf("unexpected delayed member %v", mset[1])
+	}
+
+	if mset.SetPoolMembers(desired) {
+		t.Error("nothing should be changed on the second run")
+	}
+}
+
+func TestSetHorizons(t *testing.T) {
+	mset := mongo.ConfigMembers{
+		{ID: 0, Host: "34.1.2.3:27017"},
+		{ID: 1, Host: "rs0-1.cluster-rs0.ns.svc.cluster.local:27017"},
+	}
+	desired := mongo.ConfigMembers{
+		{Host: "rs0-0.cluster-rs0.ns.svc.cluster.local:27017", Horizons: map[string]string{"external": "34.1.2.3:27017"}},
+		{Host: "rs0-1.cluster-rs0.ns.svc.cluster.local:27017", Horizons: map[string]string{"external": "34.1.2.4:27017"}},
+	}
+
+	if !mset.SetHorizons(desired) {
+		t.Fatal("the horizons should be set")
+	}
+	for i, m := range mset {
+		if m.ID != i || m.Host != desired[i].Host || m.Horizons["external"] != desired[i].Horizons["external"] {
+			t.Errorf("member %d want %v, have %v", i, desired[i], m)
+		}
+	}
+
+	if mset.SetHorizons(desired) {
+		t.Error("nothing should be changed on the second run")
+	}
+}
+
+func TestVotingMajority(t *testing.T) {
+	cases := []struct {
+		voters   int32
+		majority int32
+	}{
+		{0, 1},
+		{1, 1},
+		{2, 1},
+		{3, 2},
+		{4, 2},
+		{5, 3},
+		{7, 4},
+		{8, 4},
+		{10, 4},
+	}
+
+	for _, c := range cases {
+		if got := mongo.VotingMajority(c.voters); got != c.majority {
+			t.Errorf("VotingMajority(%d) = %d, want %d", c.voters, got, c.majority)
+		}
+	}
+}
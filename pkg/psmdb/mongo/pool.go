@@ -0,0 +1,233 @@
+package mongo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// DefaultPool is the connection pool shared by the operator's controllers
+var DefaultPool = NewPool()
+
+// Conn is a connection kept in the Pool
+type Conn interface {
+	// Ping checks whether the connection is still usable
+	Ping(ctx context.Context) error
+	// Close closes the connection
+	Close(ctx context.Context) error
+}
+
+// Pool keeps connections to the replsets between reconciles, so TLS
+// handshakes and authentication aren't paid on every reconcile.
+// Each slot (e.g. a replset of the cluster used with the certain user)
+// holds one connection. It's replaced once the slot is requested
+// with another key, i.e. after the replset members or the credentials
+// are changed, or if the connection doesn't respond to ping.
+type Pool struct {
+	mu    sync.Mutex
+	slots map[string]*poolEntry
+
+	hits   uint64
+	misses uint64
+}
+
+type poolEntry struct {
+	key  string
+	conn Conn
+	// refs is the number of the connection's users,
+	// the stale connection is closed by the last of them
+	refs  int
+	stale bool
+}
+
+// NewPool creates an empty connection pool
+func NewPool() *Pool {
+	return &Pool{
+		slots: make(map[string]*poolEntry),
+	}
+}
+
+// Get returns the connection of the slot if it was dialed with the same
+// key and is alive, or dials a new one. The returned release func should
+// be called once the connection isn't used anymore instead of closing it.
+func (p *Pool) Get(slot, key string, dial func() (Conn, error)) (Conn, func(), error) {
+	p.mu.Lock()
+	e, ok := p.slots[slot]
+	if ok && e.key == key {
+		e.refs++
+	}
+	p.mu.Unlock()
+
+	if ok && e.key == key {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err := e.conn.Ping(ctx)
+		cancel()
+		if err == nil {
+			atomic.AddUint64(&p.hits, 1)
+			return e.conn, p.releaseFunc(e), nil
+		}
+
+		log.Info("cached connection is broken, reconnecting", "slot", slot, "error", err.Error())
+		p.mu.Lock()
+		p.retire(slot, e)
+		p.mu.Unlock()
+		p.release(e)
+	}
+
+	atomic.AddUint64(&p.misses, 1)
+	conn, err := dial()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n := &poolEntry{key: key, conn: conn, refs: 1}
+	p.mu.Lock()
+	old, ok := p.slots[slot]
+	if ok {
+		p.retire(slot, old)
+	}
+	// the replaced connection is closed here if nobody uses it,
+	// otherwise by the last of its users
+	closeOld := ok && old.refs == 0
+	p.slots[slot] = n
+	p.mu.Unlock()
+
+	if closeOld {
+		closeConn(old.conn)
+	}
+
+	return conn, p.releaseFunc(n), nil
+}
+
+// Remove closes connections of the slots with the given prefix,
+// e.g. of the deleted or paused cluster
+func (p *Pool) Remove(prefix string) {
+	var closing []Conn
+
+	p.mu.Lock()
+	for slot, e := range p.slots {
+		if !strings.HasPrefix(slot, prefix) {
+			continue
+		}
+		p.retire(slot, e)
+		if e.refs == 0 {
+			closing = append(closing, e.conn)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, c := range closing {
+		closeConn(c)
+	}
+}
+
+// Stats returns the number of the requests served by the cached
+// connections and the ones which needed a new connection
+func (p *Pool) Stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&p.hits), atomic.LoadUint64(&p.misses)
+}
+
+// retire removes the entry from the slot. The pool's mutex should be held.
+func (p *Pool) retire(slot string, e *poolEntry) {
+	e.stale = true
+	if p.slots[slot] == e {
+		delete(p.slots, slot)
+	}
+}
+
+func (p *Pool) releaseFunc(e *poolEntry) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { p.release(e) })
+	}
+}
+
+func (p *Pool) release(e *poolEntry) {
+	p.mu.Lock()
+	e.refs--
+	closing := e.stale && e.refs == 0
+	p.mu.Unlock()
+
+	if closing {
+		closeConn(e.conn)
+	}
+}
+
+func closeConn(c Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := c.Close(ctx)
+	if err != nil {
+		log.Error(err, "failed to close connection")
+	}
+}
+
+// Key returns the hash of the given connection parameters
+func Key(params ...string) string {
+	h := sha256.New()
+	for _, p := range params {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ConfigKey returns the key of the connection dialed with the config.
+// It changes with the replset members, credentials and client certificates.
+func ConfigKey(conf *Config) string {
+	hosts := append([]string(nil), conf.Hosts...)
+	sort.Strings(hosts)
+
+	params := []string{conf.ReplSetName, strings.Join(hosts, ","), conf.Username, conf.Password}
+	if conf.Direct {
+		params = append(params, "direct")
+	}
+	if conf.TLSConf != nil {
+		for _, cert := range conf.TLSConf.Certificates {
+			for _, der := range cert.Certificate {
+				params = append(params, string(der))
+			}
+		}
+	}
+
+	return Key(params...)
+}
+
+type clientConn struct {
+	*mongo.Client
+}
+
+func (c clientConn) Ping(ctx context.Context) error {
+	return c.Client.Ping(ctx, readpref.Primary())
+}
+
+func (c clientConn) Close(ctx context.Context) error {
+	return c.Client.Disconnect(ctx)
+}
+
+// Client returns the client of the slot from the pool, dialing it
+// with the config if needed
+func (p *Pool) Client(slot string, conf *Config) (*mongo.Client, func(), error) {
+	conn, release, err := p.Get(slot, ConfigKey(conf), func() (Conn, error) {
+		client, err := Dial(conf)
+		if err != nil {
+			return nil, err
+		}
+		return clientConn{client}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn.(clientConn).Client, release, nil
+}
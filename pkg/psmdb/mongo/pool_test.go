@@ -0,0 +1,86 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+)
+
+type fakeConn struct {
+	pingErr error
+	closed  bool
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	return c.pingErr
+}
+
+func (c *fakeConn) Close(ctx context.Context) error {
+	c.closed = true
+	return nil
+}
+
+func TestPool(t *testing.T) {
+	p := mongo.NewPool()
+
+	var dialed []*fakeConn
+	dial := func() (mongo.Conn, error) {
+		c := &fakeConn{}
+		dialed = append(dialed, c)
+		return c, nil
+	}
+
+	c1, release, err := p.Get("ns/cluster/rs0", "k1", dial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	c2, release, err := p.Get("ns/cluster/rs0", "k1", dial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 != c2 {
+		t.Error("the cached connection should be reused")
+	}
+
+	// the key is changed while the old connection is in use
+	c3, release3, err := p.Get("ns/cluster/rs0", "k2", dial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c3 == c1 {
+		t.Error("a new connection should be dialed for the new key")
+	}
+	if dialed[0].closed {
+		t.Error("the replaced connection shouldn't be closed while in use")
+	}
+	release()
+	if !dialed[0].closed {
+		t.Error("the replaced connection should be closed by its last user")
+	}
+
+	// the broken connection is replaced
+	dialed[1].pingErr = errors.New("connection refused")
+	release3()
+	c4, release, err := p.Get("ns/cluster/rs0", "k2", dial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c4 == c3 || !dialed[1].closed {
+		t.Error("the broken connection should be closed and replaced")
+	}
+	release()
+
+	p.Remove("ns/cluster/")
+	if !dialed[2].closed {
+		t.Error("the connection of the removed cluster should be closed")
+	}
+
+	hits, misses := p.Stats()
+	if hits != 1 || misses != 3 {
+		t.Errorf("unexpected stats: %d hits, %d misses", hits, misses)
+	}
+}
@@ -0,0 +1,284 @@
+package psmdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+// MongosName returns the name of mongos Deployment and Service
+func MongosName(m *api.PerconaServerMongoDB) string {
+	return m.Name + "-mongos"
+}
+
+// MongosLabels returns labels for mongos pods
+func MongosLabels(m *api.PerconaServerMongoDB) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "percona-server-mongodb",
+		"app.kubernetes.io/instance":   m.Name,
+		"app.kubernetes.io/managed-by": "percona-server-mongodb-operator",
+		"app.kubernetes.io/part-of":    "percona-server-mongodb",
+		"app.kubernetes.io/component":  "mongos",
+	}
+}
+
+// MongosDeployment returns a mongos Deployment object
+func MongosDeployment(m *api.PerconaServerMongoDB) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MongosName(m),
+			Namespace: m.Namespace,
+		},
+	}
+}
+
+// MongosDeploymentSpec returns spec for mongos Deployment
+func MongosDeploymentSpec(m *api.PerconaServerMongoDB, ikeyName string, initContainers []corev1.Container) (appsv1.DeploymentSpec, error) {
+	ms := m.Spec.Sharding.Mongos
+
+	ls := MongosLabels(m)
+	for k, v := range ms.MultiAZ.Labels {
+		if _, ok := ls[k]; !ok {
+			ls[k] = v
+		}
+	}
+
+	resources, err := CreateResources(ms.ResourcesSpec)
+	if err != nil {
+		return appsv1.DeploymentSpec{}, fmt.Errorf("resource creation: %v", err)
+	}
+
+	c, err := mongosContainer(m, resources, ikeyName)
+	if err != nil {
+		return appsv1.DeploymentSpec{}, fmt.Errorf("failed to create container %v", err)
+	}
+
+	for i := range initContainers {
+		initContainers[i].Resources.Limits = c.Resources.Limits
+		initContainers[i].Resources.Requests = c.Resources.Requests
+	}
+
+	return appsv1.DeploymentSpec{
+		Replicas: &ms.Size,
+		Selector: &metav1.LabelSelector{
+			MatchLabels: ls,
+		},
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      ls,
+				Annotations: ms.MultiAZ.Annotations,
+			},
+			Spec: corev1.PodSpec{
+				SecurityContext:   ms.PodSecurityContext,
+				Affinity:          PodAffinity(ms.MultiAZ.Affinity, ls),
+				NodeSelector:      ms.MultiAZ.NodeSelector,
+				Tolerations:       ms.MultiAZ.Tolerations,
+				PriorityClassName: ms.MultiAZ.PriorityClassName,
+				RestartPolicy:     corev1.RestartPolicyAlways,
+				ImagePullSecrets:  m.Spec.ImagePullSecrets,
+				Containers:        []corev1.Container{c},
+				InitContainers:    initContainers,
+				Volumes:           mongosVolumes(m, ikeyName),
+				SchedulerName:     m.Spec.SchedulerName,
+			},
+		},
+	}, nil
+}
+
+func mongosContainer(m *api.PerconaServerMongoDB, resources corev1.ResourceRequirements, ikeyName string) (corev1.Container, error) {
+	ms := m.Spec.Sharding.Mongos
+	fvar := false
+
+	cfgRs := m.ConfigsvrReplset()
+	if cfgRs == nil {
+		return corev1.Container{}, fmt.Errorf("no replset with clusterRole %s", api.ClusterRoleConfigSvr)
+	}
+
+	return corev1.Container{
+		Name:            "mongos",
+		Image:           m.Spec.Image,
+		ImagePullPolicy: m.Spec.ImagePullPolicy,
+		Command:         []string{"/data/db/ps-entry.sh"},
+		Args:            mongosContainerArgs(m, cfgRs),
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          mongodPortName,
+				HostPort:      ms.HostPort,
+				ContainerPort: ms.Port,
+			},
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "MONGODB_PORT",
+				Value: strconv.Itoa(int(ms.Port)),
+			},
+		},
+		EnvFrom: []corev1.EnvFromSource{
+			{
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: "internal-" + m.Name + "-users",
+					},
+					Optional: &fvar,
+				},
+			},
+		},
+		WorkingDir:      MongodContainerDataDir,
+		LivenessProbe:   ms.LivenessProbe,
+		ReadinessProbe:  ms.ReadinessProbe,
+		Resources:       resources,
+		SecurityContext: ms.ContainerSecurityContext,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      MongodDataVolClaimName,
+				MountPath: MongodContainerDataDir,
+			},
+			{
+				Name:      ikeyName,
+				MountPath: mongodSecretsDir,
+				ReadOnly:  true,
+			},
+			{
+				Name:      "ssl",
+				MountPath: sslDir,
+				ReadOnly:  true,
+			},
+			{
+				Name:      "ssl-internal",
+				MountPath: sslInternalDir,
+				ReadOnly:  true,
+			},
+		},
+	}, nil
+}
+
+// mongosContainerArgs returns the args to pass to the mongos container
+func mongosContainerArgs(m *api.PerconaServerMongoDB, cfgRs *api.ReplsetSpec) []string {
+	cfgHosts := make([]string, 0, cfgRs.Size)
+	for i := 0; i < int(cfgRs.Size); i++ {
+		cfgHosts = append(cfgHosts, getAddr(m, m.Name+"-"+cfgRs.Name+"-"+strconv.Itoa(i), cfgRs.Name))
+	}
+
+	args := []string{
+		"mongos",
+		"--bind_ip_all",
+		"--port=" + strconv.Itoa(int(m.Spec.Sharding.Mongos.Port)),
+		"--configdb=" + cfgRs.Name + "/" + strings.Join(cfgHosts, ","),
+		"--relaxPermChecks",
+		"--sslAllowInvalidCertificates",
+	}
+
+	if m.Spec.UnsafeConf {
+		args = append(args,
+			"--clusterAuthMode=keyFile",
+			"--keyFile="+mongodSecretsDir+"/mongodb-key",
+		)
+	} else {
+		args = append(args,
+			"--sslMode=preferSSL",
+			"--clusterAuthMode=x509",
+		)
+	}
+
+	return args
+}
+
+func mongosVolumes(m *api.PerconaServerMongoDB, ikeyName string) []corev1.Volume {
+	fvar, tvar := false, true
+
+	return []corev1.Volume{
+		{
+			Name: ikeyName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					DefaultMode: &secretFileMode,
+					SecretName:  ikeyName,
+					Optional:    &fvar,
+				},
+			},
+		},
+		{
+			Name: "ssl",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  m.Spec.Secrets.SSL,
+					Optional:    &m.Spec.UnsafeConf,
+					DefaultMode: &secretFileMode,
+				},
+			},
+		},
+		{
+			Name: "ssl-internal",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  m.Spec.Secrets.SSLInternal,
+					Optional:    &tvar,
+					DefaultMode: &secretFileMode,
+				},
+			},
+		},
+		{
+			Name: MongodDataVolClaimName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+}
+
+// MongosService returns a Service object for mongos
+func MongosService(m *api.PerconaServerMongoDB) *corev1.Service {
+	ms := m.Spec.Sharding.Mongos
+
+	svc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MongosName(m),
+			Namespace: m.Namespace,
+			Labels:    MongosLabels(m),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       mongodPortName,
+					Port:       ms.Port,
+					TargetPort: intstr.FromInt(int(ms.Port)),
+				},
+			},
+			Selector: MongosLabels(m),
+		},
+	}
+
+	switch ms.Expose.ExposeType {
+	case corev1.ServiceTypeNodePort:
+		svc.Spec.Type = corev1.ServiceTypeNodePort
+		svc.Spec.ExternalTrafficPolicy = "Local"
+	case corev1.ServiceTypeLoadBalancer:
+		svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+		svc.Spec.ExternalTrafficPolicy = "Cluster"
+	default:
+		svc.Spec.Type = corev1.ServiceTypeClusterIP
+	}
+
+	return svc
+}
+
+// MongosHost returns the in-cluster host:port address of the mongos service
+func MongosHost(m *api.PerconaServerMongoDB) string {
+	return strings.Join([]string{MongosName(m), m.Namespace, m.Spec.ClusterServiceDNSSuffix}, ".") +
+		":" + strconv.Itoa(int(m.Spec.Sharding.Mongos.Port))
+}
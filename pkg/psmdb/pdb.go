@@ -8,13 +8,19 @@ import (
 )
 
 func PodDisruptionBudget(spec *api.PodDisruptionBudgetSpec, labels map[string]string, namespace string) *policyv1beta1.PodDisruptionBudget {
+	name := labels["app.kubernetes.io/instance"] + "-" + labels["app.kubernetes.io/component"]
+	// mongos pods don't belong to any replset
+	if rs, ok := labels["app.kubernetes.io/replset"]; ok {
+		name += "-" + rs
+	}
+
 	return &policyv1beta1.PodDisruptionBudget{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "policy/v1beta1",
 			Kind:       "PodDisruptionBudget",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      labels["app.kubernetes.io/instance"] + "-" + labels["app.kubernetes.io/component"] + "-" + labels["app.kubernetes.io/replset"],
+			Name:      name,
 			Namespace: namespace,
 		},
 		Spec: policyv1beta1.PodDisruptionBudgetSpec{
@@ -53,6 +53,64 @@ func Service(m *api.PerconaServerMongoDB, replset *api.ReplsetSpec) *corev1.Serv
 	}
 }
 
+// LabelRole is the label of the mongod pods with their current role in the replset
+const LabelRole = "percona.com/role"
+
+// Roles of the replset members set in the LabelRole
+const (
+	RolePrimary   = "primary"
+	RoleSecondary = "secondary"
+	RoleArbiter   = "arbiter"
+	// RoleHidden is the role of the hidden and delayed secondaries,
+	// which shouldn't get reads sent to the secondaries
+	RoleHidden = "hidden"
+)
+
+// RoleServiceName returns the name of the service selecting
+// the replset members with the given role
+func RoleServiceName(m *api.PerconaServerMongoDB, replset *api.ReplsetSpec, role string) string {
+	if role == RoleSecondary {
+		return m.Name + "-" + replset.Name + "-secondaries"
+	}
+
+	return m.Name + "-" + replset.Name + "-" + role
+}
+
+// RoleService returns a ClusterIP Service of the replset members with the given role
+func RoleService(m *api.PerconaServerMongoDB, replset *api.ReplsetSpec, role string) *corev1.Service {
+	ls := map[string]string{
+		"app.kubernetes.io/name":       "percona-server-mongodb",
+		"app.kubernetes.io/instance":   m.Name,
+		"app.kubernetes.io/replset":    replset.Name,
+		"app.kubernetes.io/managed-by": "percona-server-mongodb-operator",
+		"app.kubernetes.io/part-of":    "percona-server-mongodb",
+		LabelRole:                      role,
+	}
+
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        RoleServiceName(m, replset, role),
+			Namespace:   m.Namespace,
+			Annotations: m.Spec.Mongod.ServiceAnnotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       mongodPortName,
+					Port:       m.Spec.Mongod.Net.Port,
+					TargetPort: intstr.FromInt(int(m.Spec.Mongod.Net.Port)),
+				},
+			},
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: ls,
+		},
+	}
+}
+
 // ExternalService returns a Service object needs to serve external connections
 func ExternalService(m *api.PerconaServerMongoDB, replset *api.ReplsetSpec, podName string) *corev1.Service {
 	svc := &corev1.Service{
@@ -201,15 +259,41 @@ func GetReplsetAddrs(cl client.Client, m *api.PerconaServerMongoDB, replset *api
 	return addrs, nil
 }
 
+// HorizonExternal is the name of the replset horizon with
+// the addresses of the members' external services
+const HorizonExternal = "external"
+
+// UseHorizons returns whether the members of the exposed replset keep their
+// internal hosts and get the external addresses as the horizon. The horizons
+// are chosen by the clients with SNI, so they need TLS.
+func UseHorizons(m *api.PerconaServerMongoDB, replset *api.ReplsetSpec) bool {
+	return replset.Expose.Enabled && !m.Spec.UnsafeConf && m.CompareVersion("1.6.0") >= 0
+}
+
 // MongoHost returns the mongo host for given pod
 func MongoHost(cl client.Client, m *api.PerconaServerMongoDB, replset *api.ReplsetSpec, pod corev1.Pod) (string, error) {
-	if replset.Expose.Enabled {
+	if replset.Expose.Enabled && !UseHorizons(m, replset) {
 		return getExtAddr(cl, m.Namespace, pod)
 	}
 
 	return getAddr(m, pod.Name, replset.Name), nil
 }
 
+// MongoHorizons returns the horizons of the pod's member, i.e. the address
+// of its external service, or nil if the replset doesn't use horizons
+func MongoHorizons(cl client.Client, m *api.PerconaServerMongoDB, replset *api.ReplsetSpec, pod corev1.Pod) (map[string]string, error) {
+	if !UseHorizons(m, replset) {
+		return nil, nil
+	}
+
+	addr, err := getExtAddr(cl, m.Namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{HorizonExternal: addr}, nil
+}
+
 func getExtAddr(cl client.Client, namespace string, pod corev1.Pod) (string, error) {
 	svc, err := getExtServices(cl, namespace, pod.Name)
 	if err != nil {
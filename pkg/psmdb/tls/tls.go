@@ -9,6 +9,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"math/big"
+	"net"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,8 +17,19 @@ import (
 
 var validityNotAfter = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
 
-// Issue returns CA certificate, TLS certificate and TLS private key
+// Issue returns CA certificate, TLS certificate and TLS private key.
+// The hosts which are IP addresses get to the IP SANs of the certificate.
 func Issue(hosts []string) (caCert []byte, tlsCert []byte, tlsKey []byte, err error) {
+	var dnsNames []string
+	var ips []net.IP
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			ips = append(ips, ip)
+			continue
+		}
+		dnsNames = append(dnsNames, h)
+	}
+
 	rsaBits := 2048
 	priv, err := rsa.GenerateKey(rand.Reader, rsaBits)
 	if err != nil {
@@ -69,7 +81,8 @@ func Issue(hosts []string) (caCert []byte, tlsCert []byte, tlsKey []byte, err er
 		Issuer:                issuer,
 		NotBefore:             time.Now(),
 		NotAfter:              validityNotAfter,
-		DNSNames:              hosts,
+		DNSNames:              dnsNames,
+		IPAddresses:           ips,
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
 		BasicConstraintsValid: true,
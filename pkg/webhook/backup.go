@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+type backupHook struct {
+	client  client.Client
+	decoder *admission.Decoder
+}
+
+// mutate sets the defaults of the backup spec
+func (h *backupHook) mutate(ctx context.Context, req admission.Request) admission.Response {
+	bcp := &api.PerconaServerMongoDBBackup{}
+	err := h.decoder.Decode(req, bcp)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// invalid specs are rejected by the validating webhook
+	err = bcp.CheckFields()
+	if err != nil {
+		return admission.Allowed("")
+	}
+
+	return patchResponse(req, bcp)
+}
+
+// validate checks the backup spec and that the storage is defined
+// in the cluster. The backup can be deleted with the object only
+// from the storages the operator can reach.
+func (h *backupHook) validate(ctx context.Context, req admission.Request) admission.Response {
+	bcp := &api.PerconaServerMongoDBBackup{}
+	err := h.decoder.Decode(req, bcp)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	err = bcp.CheckFields()
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	stg, err := clusterStorage(ctx, h.client, bcp.Namespace, bcp.Spec.PSMDBCluster, bcp.Spec.StorageName)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	if bcp.Spec.DeleteFromStorage && !stg.Type.OperatorAccessible() {
+		return admission.Denied(fmt.Sprintf("deleteFromStorage isn't supported for %s storage %s", stg.Type, bcp.Spec.StorageName))
+	}
+
+	return admission.Allowed("")
+}
+
+// checkStorage denies the request if there is no such cluster
+// or the storage isn't defined in the cluster
+func checkStorage(ctx context.Context, cl client.Client, namespace, clusterName, storageName string) admission.Response {
+	_, err := clusterStorage(ctx, cl, namespace, clusterName, storageName)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	return admission.Allowed("")
+}
+
+// clusterStorage returns the storage defined in the cluster
+func clusterStorage(ctx context.Context, cl client.Client, namespace, clusterName, storageName string) (api.BackupStorageSpec, error) {
+	cluster := &api.PerconaServerMongoDB{}
+	err := cl.Get(ctx, types.NamespacedName{Name: clusterName, Namespace: namespace}, cluster)
+	if err != nil {
+		return api.BackupStorageSpec{}, fmt.Errorf("get cluster %s: %v", clusterName, err)
+	}
+
+	stg, ok := cluster.Spec.Backup.Storages[storageName]
+	if !ok {
+		return api.BackupStorageSpec{}, fmt.Errorf("unknown storage %q of cluster %s", storageName, clusterName)
+	}
+
+	return stg, nil
+}
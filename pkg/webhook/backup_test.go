@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+func TestBackupValidate(t *testing.T) {
+	scheme := testScheme(t)
+	cluster := testCluster(func(cr *api.PerconaServerMongoDB) {
+		cr.Spec.Backup.Storages = map[string]api.BackupStorageSpec{
+			"s3-us-west": {
+				Type: api.BackupStorageS3,
+				S3:   api.BackupStorageS3Spec{Bucket: "bucket", CredentialsSecret: "s3-secret"},
+			},
+			"fs-nfs": {
+				Type:       api.BackupStorageFilesystem,
+				Filesystem: &api.BackupStorageFilesystemSpec{NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/backups"}},
+			},
+		}
+	})
+	hook := &backupHook{
+		client: &stubClient{
+			objs: map[client.ObjectKey]runtime.Object{
+				{Name: cluster.Name, Namespace: cluster.Namespace}: cluster,
+			},
+		},
+		decoder: testDecoder(t, scheme),
+	}
+
+	bcp := func(clusterName, storageName string) *api.PerconaServerMongoDBBackup {
+		b := &api.PerconaServerMongoDBBackup{}
+		b.Name = "backup1"
+		b.Namespace = "psmdb"
+		b.Spec.PSMDBCluster = clusterName
+		b.Spec.StorageName = storageName
+		return b
+	}
+	deleted := func(b *api.PerconaServerMongoDBBackup) *api.PerconaServerMongoDBBackup {
+		b.Spec.DeleteFromStorage = true
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		bcp     *api.PerconaServerMongoDBBackup
+		allowed bool
+	}{
+		{
+			name:    "known storage",
+			bcp:     bcp("my-cluster", "s3-us-west"),
+			allowed: true,
+		},
+		{
+			name: "unknown storage",
+			bcp:  bcp("my-cluster", "s3-eu-west"),
+		},
+		{
+			name: "no storage",
+			bcp:  bcp("my-cluster", ""),
+		},
+		{
+			name:    "filesystem storage",
+			bcp:     bcp("my-cluster", "fs-nfs"),
+			allowed: true,
+		},
+		{
+			name:    "deleteFromStorage of s3 storage",
+			bcp:     deleted(bcp("my-cluster", "s3-us-west")),
+			allowed: true,
+		},
+		{
+			name: "deleteFromStorage of filesystem storage",
+			bcp:  deleted(bcp("my-cluster", "fs-nfs")),
+		},
+		{
+			name: "unknown cluster",
+			bcp:  bcp("other-cluster", "s3-us-west"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := hook.validate(context.Background(), admissionRequest(t, scheme, admissionv1beta1.Create, tt.bcp, nil))
+			if resp.Allowed != tt.allowed {
+				t.Errorf("validate() allowed = %v, want %v: %v", resp.Allowed, tt.allowed, resp.Result)
+			}
+		})
+	}
+}
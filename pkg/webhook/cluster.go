@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/pkg/psmdb/mongo"
+	"github.com/percona/percona-server-mongodb-operator/version"
+)
+
+type clusterHook struct {
+	client   client.Client
+	decoder  *admission.Decoder
+	platform version.Platform
+}
+
+// mutate sets the version of the created cluster if it's not specified.
+// The rest of the defaults are set by the reconciler and aren't stored,
+// so they can be changed by the next versions of the operator.
+func (h *clusterHook) mutate(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1beta1.Create {
+		return admission.Allowed("")
+	}
+
+	cr := &api.PerconaServerMongoDB{}
+	err := h.decoder.Decode(req, cr)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if cr.Spec.CRVersion != "" {
+		return admission.Allowed("")
+	}
+
+	// invalid specs are rejected by the validating webhook
+	err = cr.SetVersion()
+	if err != nil {
+		return admission.Allowed("")
+	}
+
+	return patchResponse(req, cr)
+}
+
+// defaults returns a copy of the cluster with the defaults set. Unlike
+// the reconciler, it doesn't change the size of replsets to the safe
+// configuration or to zero on pause, so these decisions aren't saved.
+func (h *clusterHook) defaults(cr *api.PerconaServerMongoDB) (*api.PerconaServerMongoDB, error) {
+	d := cr.DeepCopy()
+	d.Spec.UnsafeConf = true
+	d.Spec.Pause = false
+
+	err := d.CheckNSetDefaults(h.platform, log)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Spec.UnsafeConf = cr.Spec.UnsafeConf
+	d.Spec.Pause = cr.Spec.Pause
+	d.Status = cr.Status
+
+	return d, nil
+}
+
+// validate rejects cluster specs the reconciler would fail on
+// or would silently change
+func (h *clusterHook) validate(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1beta1.Delete {
+		return h.validateDelete(req)
+	}
+
+	cr := &api.PerconaServerMongoDB{}
+	err := h.decoder.Decode(req, cr)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	old := &api.PerconaServerMongoDB{}
+	if req.Operation == admissionv1beta1.Update {
+		err = h.decoder.DecodeRaw(req.OldObject, old)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		// the operator's own writes of metadata and status shouldn't
+		// be rejected for the specs accepted before the webhook
+		if reflect.DeepEqual(old.Spec, cr.Spec) {
+			return admission.Allowed("")
+		}
+	}
+
+	if !cr.Spec.UnsafeConf {
+		for _, rs := range cr.Spec.Replsets {
+			if req.Operation == admissionv1beta1.Update && !sizeChanged(old, rs) {
+				continue
+			}
+			err := rs.CheckSafeConfig()
+			if err != nil {
+				return admission.Denied(err.Error())
+			}
+		}
+	}
+
+	d, err := h.defaults(cr)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	if d.Spec.Backup.Enabled {
+		for _, task := range d.Spec.Backup.Tasks {
+			if _, ok := d.Spec.Backup.Storages[task.StorageName]; !ok {
+				return admission.Denied(fmt.Sprintf("backup task %s: unknown storage %q", task.Name, task.StorageName))
+			}
+		}
+
+		err = checkStorageClaims(ctx, h.client, req.Namespace, d)
+		if err != nil {
+			return admission.Denied(err.Error())
+		}
+	}
+
+	if req.Operation != admissionv1beta1.Update {
+		return admission.Allowed("")
+	}
+
+	// the data of the deployed cluster depends on these options
+	if old.Status.State == "" {
+		return admission.Allowed("")
+	}
+	oldd, err := h.defaults(old)
+	if err != nil {
+		return admission.Allowed("")
+	}
+
+	if old.Spec.DeletionProtection {
+		err := checkProtectedChanges(oldd, d)
+		if err != nil {
+			return admission.Denied(err.Error() + " while deletionProtection is on")
+		}
+	}
+
+	if oldd.Spec.Mongod.Storage.Engine != d.Spec.Mongod.Storage.Engine {
+		return admission.Denied(fmt.Sprintf("storage engine can't be changed from %s to %s on the deployed cluster",
+			oldd.Spec.Mongod.Storage.Engine, d.Spec.Mongod.Storage.Engine))
+	}
+	if *oldd.Spec.Mongod.Security.EnableEncryption != *d.Spec.Mongod.Security.EnableEncryption {
+		return admission.Denied("encryption can't be turned on or off on the deployed cluster")
+	}
+
+	return admission.Allowed("")
+}
+
+// checkStorageClaims rejects filesystem storages on the claims which can't be
+// mounted by the pods of all replsets at once. The claims which don't exist
+// yet aren't checked.
+func checkStorageClaims(ctx context.Context, cl client.Client, namespace string, cr *api.PerconaServerMongoDB) error {
+	for name, stg := range cr.Spec.Backup.Storages {
+		if stg.Type != api.BackupStorageFilesystem || stg.Filesystem.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		claim := stg.Filesystem.PersistentVolumeClaim.ClaimName
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := cl.Get(ctx, types.NamespacedName{Name: claim, Namespace: namespace}, pvc)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("backup storage %s: get claim %s: %v", name, claim, err)
+		}
+
+		rwx := false
+		for _, mode := range pvc.Spec.AccessModes {
+			if mode == corev1.ReadWriteMany {
+				rwx = true
+			}
+		}
+		if !rwx {
+			return fmt.Errorf("backup storage %s: claim %s should be ReadWriteMany as it's mounted by all members", name, claim)
+		}
+	}
+
+	return nil
+}
+
+// sizeChanged returns whether the replset is new or its members
+// or arbiters are changed, so its safe config should be checked
+func sizeChanged(old *api.PerconaServerMongoDB, rs *api.ReplsetSpec) bool {
+	for _, oldrs := range old.Spec.Replsets {
+		if oldrs.Name != rs.Name {
+			continue
+		}
+		return oldrs.Size != rs.Size || !reflect.DeepEqual(oldrs.Arbiter, rs.Arbiter)
+	}
+
+	return true
+}
+
+// validateDelete rejects the deletion of the protected cluster
+func (h *clusterHook) validateDelete(req admission.Request) admission.Response {
+	old := &api.PerconaServerMongoDB{}
+	err := h.decoder.DecodeRaw(req.OldObject, old)
+	if err != nil {
+		// the old object is sent since k8s 1.15,
+		// the finalizer protects the cluster on the older ones
+		return admission.Allowed("")
+	}
+
+	if old.Spec.DeletionProtection {
+		return admission.Denied("the cluster can't be deleted while deletionProtection is on")
+	}
+
+	return admission.Allowed("")
+}
+
+// checkProtectedChanges returns an error if the spec change would lose
+// the data of the protected cluster. The storage engine change is
+// rejected for every deployed cluster.
+func checkProtectedChanges(old, cr *api.PerconaServerMongoDB) error {
+	replsets := make(map[string]*api.ReplsetSpec, len(cr.Spec.Replsets))
+	for _, rs := range cr.Spec.Replsets {
+		replsets[rs.Name] = rs
+	}
+
+	for _, oldrs := range old.Spec.Replsets {
+		rs, ok := replsets[oldrs.Name]
+		if !ok {
+			return fmt.Errorf("replset %s can't be removed", oldrs.Name)
+		}
+
+		if majority := votingMajority(oldrs); rs.Size < majority {
+			return fmt.Errorf("replset %s can't be scaled down to %d below the voting majority of %d", rs.Name, rs.Size, majority)
+		}
+	}
+
+	return nil
+}
+
+// votingMajority returns the majority of the replset's voting members.
+// Arbiters vote, the members of the pools don't.
+func votingMajority(rs *api.ReplsetSpec) int32 {
+	voters := rs.Size
+	if rs.Arbiter.Enabled {
+		voters += rs.Arbiter.Size
+	}
+
+	return mongo.VotingMajority(voters)
+}
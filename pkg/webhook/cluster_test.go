@@ -0,0 +1,309 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/percona/percona-server-mongodb-operator/pkg/apis"
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+	"github.com/percona/percona-server-mongodb-operator/version"
+)
+
+// stubClient serves Get from the given objects,
+// the rest of the calls panic
+type stubClient struct {
+	client.Client
+	objs map[client.ObjectKey]runtime.Object
+}
+
+func (c *stubClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	o, ok := c.objs[key]
+	if !ok {
+		return k8serrors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(o.DeepCopyObject()).Elem())
+	return nil
+}
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	err := apis.AddToScheme(scheme)
+	if err != nil {
+		t.Fatalf("add to scheme: %v", err)
+	}
+
+	return scheme
+}
+
+func testDecoder(t *testing.T, scheme *runtime.Scheme) *admission.Decoder {
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("create decoder: %v", err)
+	}
+
+	return decoder
+}
+
+// rawObject returns the object encoded as it's sent by the api server
+func rawObject(t *testing.T, scheme *runtime.Scheme, obj runtime.Object) runtime.RawExtension {
+	if obj == nil {
+		return runtime.RawExtension{}
+	}
+
+	obj = obj.DeepCopyObject()
+	gvks, _, err := scheme.ObjectKinds(obj)
+	if err != nil {
+		t.Fatalf("get object kind: %v", err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("marshal object: %v", err)
+	}
+
+	return runtime.RawExtension{Raw: raw}
+}
+
+func admissionRequest(t *testing.T, scheme *runtime.Scheme, op admissionv1beta1.Operation, obj, old runtime.Object) admission.Request {
+	return admission.Request{
+		AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: op,
+			Namespace: "psmdb",
+			Object:    rawObject(t, scheme, obj),
+			OldObject: rawObject(t, scheme, old),
+		},
+	}
+}
+
+func testCluster(mods ...func(cr *api.PerconaServerMongoDB)) *api.PerconaServerMongoDB {
+	cr := &api.PerconaServerMongoDB{}
+	cr.Name = "my-cluster"
+	cr.Namespace = "psmdb"
+	cr.Spec.Image = "percona/percona-server-mongodb:4.2"
+	cr.Spec.Replsets = []*api.ReplsetSpec{
+		{
+			Name:       "rs0",
+			Size:       3,
+			VolumeSpec: &api.VolumeSpec{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+	}
+	for _, mod := range mods {
+		mod(cr)
+	}
+
+	return cr
+}
+
+func TestClusterValidate(t *testing.T) {
+	scheme := testScheme(t)
+	hook := &clusterHook{
+		client:   &stubClient{},
+		decoder:  testDecoder(t, scheme),
+		platform: version.PlatformKubernetes,
+	}
+
+	evenSize := func(cr *api.PerconaServerMongoDB) { cr.Spec.Replsets[0].Size = 4 }
+	unsafe := func(cr *api.PerconaServerMongoDB) { cr.Spec.UnsafeConf = true }
+	deployed := func(cr *api.PerconaServerMongoDB) { cr.Status.State = api.AppStateReady }
+	inMemory := func(cr *api.PerconaServerMongoDB) {
+		cr.Spec.Mongod = &api.MongodSpec{
+			Storage: &api.MongodSpecStorage{Engine: api.StorageEngineInMemory},
+		}
+	}
+	newImage := func(cr *api.PerconaServerMongoDB) { cr.Spec.Image = "percona/percona-server-mongodb:4.4" }
+	backupTask := func(storageName string) func(cr *api.PerconaServerMongoDB) {
+		return func(cr *api.PerconaServerMongoDB) {
+			cr.Spec.Backup = api.BackupSpec{
+				Enabled: true,
+				Image:   "percona/percona-server-mongodb-operator:backup",
+				Storages: map[string]api.BackupStorageSpec{
+					"s3-us-west": {
+						Type: api.BackupStorageS3,
+						S3:   api.BackupStorageS3Spec{Bucket: "bucket", CredentialsSecret: "s3-secret"},
+					},
+				},
+				Tasks: []api.BackupTaskSpec{
+					{Name: "daily", Enabled: true, Schedule: "0 0 * * *", StorageName: storageName},
+				},
+			}
+		}
+	}
+
+	tests := []struct {
+		name    string
+		op      admissionv1beta1.Operation
+		cr      *api.PerconaServerMongoDB
+		old     *api.PerconaServerMongoDB
+		allowed bool
+	}{
+		{
+			name:    "create",
+			op:      admissionv1beta1.Create,
+			cr:      testCluster(),
+			allowed: true,
+		},
+		{
+			name: "create with even replset size",
+			op:   admissionv1beta1.Create,
+			cr:   testCluster(evenSize),
+		},
+		{
+			name:    "create with even replset size and unsafe configurations",
+			op:      admissionv1beta1.Create,
+			cr:      testCluster(evenSize, unsafe),
+			allowed: true,
+		},
+		{
+			name:    "create with backup task",
+			op:      admissionv1beta1.Create,
+			cr:      testCluster(backupTask("s3-us-west")),
+			allowed: true,
+		},
+		{
+			name: "create with backup task of unknown storage",
+			op:   admissionv1beta1.Create,
+			cr:   testCluster(backupTask("s3-eu-west")),
+		},
+		{
+			name: "scale to even replset size",
+			op:   admissionv1beta1.Update,
+			cr:   testCluster(evenSize),
+			old:  testCluster(),
+		},
+		{
+			name:    "unchanged spec with even replset size",
+			op:      admissionv1beta1.Update,
+			cr:      testCluster(evenSize, deployed),
+			old:     testCluster(evenSize),
+			allowed: true,
+		},
+		{
+			name:    "update of other fields with even replset size",
+			op:      admissionv1beta1.Update,
+			cr:      testCluster(evenSize, newImage),
+			old:     testCluster(evenSize),
+			allowed: true,
+		},
+		{
+			name: "storage engine change of deployed cluster",
+			op:   admissionv1beta1.Update,
+			cr:   testCluster(inMemory, deployed),
+			old:  testCluster(deployed),
+		},
+		{
+			name:    "storage engine change of not deployed cluster",
+			op:      admissionv1beta1.Update,
+			cr:      testCluster(inMemory),
+			old:     testCluster(),
+			allowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var old runtime.Object
+			if tt.old != nil {
+				old = tt.old
+			}
+			resp := hook.validate(context.Background(), admissionRequest(t, scheme, tt.op, tt.cr, old))
+			if resp.Allowed != tt.allowed {
+				t.Errorf("validate() allowed = %v, want %v: %v", resp.Allowed, tt.allowed, resp.Result)
+			}
+		})
+	}
+}
+
+func TestCheckProtectedChanges(t *testing.T) {
+	cluster := func(replsets ...*api.ReplsetSpec) *api.PerconaServerMongoDB {
+		return &api.PerconaServerMongoDB{
+			Spec: api.PerconaServerMongoDBSpec{Replsets: replsets},
+		}
+	}
+	rs := func(name string, size, arbiters int32) *api.ReplsetSpec {
+		return &api.ReplsetSpec{
+			Name: name,
+			Size: size,
+			Arbiter: api.Arbiter{
+				Enabled: arbiters > 0,
+				Size:    arbiters,
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		old     *api.PerconaServerMongoDB
+		cr      *api.PerconaServerMongoDB
+		wantErr bool
+	}{
+		{
+			name: "no changes",
+			old:  cluster(rs("rs0", 3, 0)),
+			cr:   cluster(rs("rs0", 3, 0)),
+		},
+		{
+			name: "scale up",
+			old:  cluster(rs("rs0", 3, 0)),
+			cr:   cluster(rs("rs0", 5, 0)),
+		},
+		{
+			name: "replset added",
+			old:  cluster(rs("rs0", 3, 0)),
+			cr:   cluster(rs("rs0", 3, 0), rs("rs1", 3, 0)),
+		},
+		{
+			name:    "replset removed",
+			old:     cluster(rs("rs0", 3, 0), rs("rs1", 3, 0)),
+			cr:      cluster(rs("rs0", 3, 0)),
+			wantErr: true,
+		},
+		{
+			name: "scale down to the majority",
+			old:  cluster(rs("rs0", 5, 0)),
+			cr:   cluster(rs("rs0", 3, 0)),
+		},
+		{
+			name:    "scale down below the majority",
+			old:     cluster(rs("rs0", 3, 0)),
+			cr:      cluster(rs("rs0", 1, 0)),
+			wantErr: true,
+		},
+		{
+			name: "even size counts as odd",
+			old:  cluster(rs("rs0", 4, 0)),
+			cr:   cluster(rs("rs0", 2, 0)),
+		},
+		{
+			name:    "arbiter votes",
+			old:     cluster(rs("rs0", 2, 1)),
+			cr:      cluster(rs("rs0", 1, 1)),
+			wantErr: true,
+		},
+		{
+			name: "voters over the max",
+			old:  cluster(rs("rs0", 9, 0)),
+			cr:   cluster(rs("rs0", 4, 0)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkProtectedChanges(tt.old, tt.cr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkProtectedChanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	api "github.com/percona/percona-server-mongodb-operator/pkg/apis/psmdb/v1"
+)
+
+type restoreHook struct {
+	client  client.Client
+	decoder *admission.Decoder
+}
+
+// validate checks the restore spec and that the storage,
+// if it's given, is defined in the cluster
+func (h *restoreHook) validate(ctx context.Context, req admission.Request) admission.Response {
+	rstr := &api.PerconaServerMongoDBRestore{}
+	err := h.decoder.Decode(req, rstr)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	err = rstr.CheckFields()
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	if rstr.Spec.StorageName == "" {
+		return admission.Allowed("")
+	}
+
+	return checkStorage(ctx, h.client, rstr.Namespace, rstr.Spec.ClusterName, rstr.Spec.StorageName)
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/percona/percona-server-mongodb-operator/version"
+)
+
+var log = logf.Log.WithName("webhook")
+
+// Port is the port the webhook server listens on
+const Port = 9443
+
+// Paths the admission webhooks are served at. They should be
+// in sync with the webhook configurations in deploy/webhook.yaml.
+const (
+	MutateClusterPath   = "/mutate-perconaservermongodb"
+	ValidateClusterPath = "/validate-perconaservermongodb"
+	MutateBackupPath    = "/mutate-perconaservermongodbbackup"
+	ValidateBackupPath  = "/validate-perconaservermongodbbackup"
+	ValidateRestorePath = "/validate-perconaservermongodbrestore"
+)
+
+// AddToManager registers the defaulting and validating webhooks
+// of the psmdb resources on the manager's webhook server
+func AddToManager(mgr manager.Manager) error {
+	sv, err := version.Server()
+	if err != nil {
+		return errors.Wrap(err, "get server version")
+	}
+
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return errors.Wrap(err, "create decoder")
+	}
+
+	cluster := &clusterHook{client: mgr.GetClient(), decoder: decoder, platform: sv.Platform}
+	bcp := &backupHook{client: mgr.GetClient(), decoder: decoder}
+	rstr := &restoreHook{client: mgr.GetClient(), decoder: decoder}
+
+	srv := mgr.GetWebhookServer()
+	srv.Port = Port
+	srv.Register(MutateClusterPath, &admission.Webhook{Handler: admission.HandlerFunc(cluster.mutate)})
+	srv.Register(ValidateClusterPath, &admission.Webhook{Handler: admission.HandlerFunc(cluster.validate)})
+	srv.Register(MutateBackupPath, &admission.Webhook{Handler: admission.HandlerFunc(bcp.mutate)})
+	srv.Register(ValidateBackupPath, &admission.Webhook{Handler: admission.HandlerFunc(bcp.validate)})
+	srv.Register(ValidateRestorePath, &admission.Webhook{Handler: admission.HandlerFunc(rstr.validate)})
+
+	return nil
+}
+
+// patchResponse returns the response with the patch turning
+// the raw object of the request into the given one
+func patchResponse(req admission.Request, obj runtime.Object) admission.Response {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, raw)
+}